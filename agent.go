@@ -0,0 +1,46 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"net"
+	"os"
+	"time"
+)
+
+const (
+	// agentOTLPHTTPPort is the well-known OTLP over HTTP port of the collector agent.
+	agentOTLPHTTPPort = "4318"
+	// agentDialTimeout limits time spent probing single agent address.
+	agentDialTimeout = 200 * time.Millisecond
+)
+
+// discoverAgentEndpoint looks for node-local collector agent listening on the
+// well-known OTLP port. Node address from HOST_IP environment variable (usually
+// set for agents deployed as Kubernetes DaemonSet) is checked before localhost.
+// Empty string is returned if no agent is found.
+func discoverAgentEndpoint() string {
+	hosts := make([]string, 0, 2)
+
+	if ip := os.Getenv("HOST_IP"); ip != "" {
+		hosts = append(hosts, ip)
+	}
+
+	hosts = append(hosts, "localhost")
+
+	for _, host := range hosts {
+		addr := net.JoinHostPort(host, agentOTLPHTTPPort)
+
+		conn, err := net.DialTimeout("tcp", addr, agentDialTimeout)
+		if err != nil {
+			continue
+		}
+
+		_ = conn.Close()
+
+		return "http://" + addr
+	}
+
+	return ""
+}
@@ -5,45 +5,191 @@ package opentelemetry
 
 import (
 	"context"
+	"os"
 
 	"azugo.io/azugo"
 	"azugo.io/core"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 // Use OpenTelemetry for tracing in Azugo application.
+//
+// If TracerProvider option is provided, it will be used instead of creating
+// new tracer provider based on the configuration.
 func Use(app *azugo.App, config *Configuration, opts ...Option) (core.Tasker, error) {
+	return use(app, config, newRouteRegistry(), opts...)
+}
+
+// UseShared OpenTelemetry for tracing in multiple Azugo applications hosted in
+// the same process (for example admin and public servers) sharing one tracer
+// provider and exporter connections.
+//
+// Tasks are returned in the same order as applications. The first task owns the
+// shared tracer provider and flushes and shuts it down when stopped, so it must
+// be added to the application that is stopped last.
+func UseShared(apps []*azugo.App, config *Configuration, opts ...Option) ([]core.Tasker, error) {
+	if len(apps) == 0 {
+		return nil, nil
+	}
+
+	routes := newRouteRegistry()
+
+	first, err := use(apps[0], config, routes, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]core.Tasker, 0, len(apps))
+	tasks = append(tasks, first)
+
+	if _, ok := first.(*noop); ok {
+		cfg := newConfig(opts...)
+
+		for _, app := range apps[1:] {
+			tasks = append(tasks, useDisabled(app, cfg))
+		}
+
+		return tasks, nil
+	}
+
+	p := ProvidersFrom(first)
+
+	shared := make([]Option, 0, len(opts)+2)
+	shared = append(shared, opts...)
+	shared = append(shared, TracerProvider(p.TracerProvider), TextMapPropagator(p.Propagator))
+
+	for _, app := range apps[1:] {
+		t, err := use(app, config, routes, shared...)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, t)
+	}
+
+	return tasks, nil
+}
+
+func use(app *azugo.App, config *Configuration, routes *routeRegistry, opts ...Option) (core.Tasker, error) {
+	flushFns := make([]func(context.Context) error, 0, 1)
 	shutdownFns := make([]func(context.Context) error, 0, 1)
 
 	if config == nil {
 		config = &Configuration{}
 	}
 
-	// If tracing is disabled, return a no-op setup.
-	if config.IsDisabled() {
-		return &noop{}, nil
+	cfg := newConfig(opts...)
+
+	var (
+		res   *resource.Resource
+		stats *exportStats
+	)
+
+	if cfg.TracerProvider == nil {
+		// Explicitly disabled tracing does not probe for the collector agent.
+		if config.Disabled || config.Exporter == ExporterNone {
+			return useDisabled(app, cfg), nil
+		}
+
+		if config.AgentDiscovery && config.Endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+			if endpoint := discoverAgentEndpoint(); endpoint != "" {
+				app.Log().Info("Open Telemetry collector agent discovered", zap.String("endpoint", endpoint))
+
+				// Discovered endpoint must not leak into the configuration of the caller.
+				c := *config
+				c.Endpoint = endpoint
+				config = &c
+			}
+		}
+
+		// If tracing is disabled, return a no-op setup. Custom exporter does not require an endpoint.
+		if cfg.traceExporter == nil && config.IsDisabled() {
+			return useDisabled(app, cfg), nil
+		}
+
+		// Report SDK and export errors to the application log.
+		if !cfg.IsolatedProviders {
+			otel.SetErrorHandler(&errorHandler{logger: app.Log()})
+		}
+
+		// Resource is shared by all providers so that all signals are
+		// attributed to the same entity.
+		res = newResource(app, config)
+
+		stats = &exportStats{}
+
+		traceProvider, err := newTraceProvider(app, config, cfg, res, stats, routes)
+		if err != nil {
+			return nil, err
+		}
+
+		flushFns = append(flushFns, traceProvider.ForceFlush)
+		shutdownFns = append(shutdownFns, traceProvider.Shutdown)
+
+		if cfg.IsolatedProviders {
+			opts = append(opts, TracerProvider(traceProvider))
+		} else {
+			otel.SetTracerProvider(traceProvider)
+		}
+
+		mp := cfg.MeterProvider
+		if mp == nil {
+			mp = otel.GetMeterProvider()
+		}
+
+		stats.register(mp)
 	}
 
-	traceProvider, err := newTraceProvider(app, config)
-	if err != nil {
-		return nil, err
+	propagator := newPropagator(config, res)
+
+	if cfg.IsolatedProviders {
+		if cfg.Propagators == nil {
+			opts = append(opts, TextMapPropagator(propagator))
+		}
+	} else {
+		// Set the global OTEL propagator
+		otel.SetTextMapPropagator(propagator)
+	}
+
+	opts = append(opts, Filter(routes.filter))
+
+	if len(config.PeerServiceMap) > 0 {
+		opts = append(opts, PeerServiceMap(config.PeerServiceMap))
 	}
 
-	shutdownFns = append(shutdownFns, traceProvider.Shutdown)
+	if len(config.Metrics.DurationBuckets) > 0 {
+		opts = append(opts, DurationHistogramBuckets(config.Metrics.DurationBuckets...))
+	}
+
+	if len(config.SpanAttributes) > 0 {
+		opts = append(opts, ServerAttributes(mapAttributes(config.SpanAttributes)...))
+	}
+
+	if len(config.IgnorePaths) > 0 {
+		opts = append(opts, FilterPath(config.IgnorePaths...))
+	}
 
-	// Set the global OTEL providers
-	otel.SetTextMapPropagator(newPropagator())
-	otel.SetTracerProvider(traceProvider)
+	app.RouterOptions().PanicHandler = newPanicHandler(config, opts...)
 
-	app.RouterOptions().PanicHandler = panicHandler
+	app.Use(middleware(config, opts...))
 
-	app.Use(middleware(opts...))
+	in := newInstrumentation(opts...)
 
-	app.Instrumentation(instr(opts...))
+	app.Instrumentation(in.record)
 
 	return &setup{
 		app:         app,
 		config:      config,
+		instr:       in,
+		res:         res,
+		stats:       stats,
+		routes:      routes,
+		filters:     len(newConfig(opts...).Filters),
+		flushFns:    flushFns,
 		shutdownFns: shutdownFns,
 	}, nil
 }
@@ -67,6 +213,34 @@ func FromContext(ctx context.Context) context.Context {
 	return sc
 }
 
+// DeferAttributes stores attributes to be set on the request server span when it ends.
+//
+// Attributes are discarded without any additional cost if the span is not sampled.
+// Later values override earlier ones with the same key.
+func DeferAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	if !trace.SpanFromContext(FromContext(ctx)).IsRecording() {
+		return
+	}
+
+	state := requestStateFromContext(ctx)
+	if state == nil {
+		return
+	}
+
+	state.deferAttributes(attrs)
+}
+
+// useDisabled returns no-op setup for disabled telemetry. Outgoing requests
+// are still signed if ClientRequestSigner option is provided, as signing must
+// not depend on telemetry being enabled.
+func useDisabled(app *azugo.App, cfg *otelcfg) core.Tasker {
+	if cfg.clientRequestSigner != nil {
+		app.Instrumentation(signClientRequests(cfg.clientRequestSigner))
+	}
+
+	return &noop{}
+}
+
 type noop struct{}
 
 func (noop) Name() string {
@@ -0,0 +1,102 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// resourceBaggagePropagator adds selected resource attributes to the baggage
+// of outgoing requests, so that downstream services can stamp the same values,
+// for example deployment environment, on their telemetry and logs.
+type resourceBaggagePropagator struct {
+	members []baggage.Member
+	next    propagation.TextMapPropagator
+}
+
+func newResourceBaggagePropagator(res *resource.Resource, keys []string, next propagation.TextMapPropagator) propagation.TextMapPropagator {
+	members := make([]baggage.Member, 0, len(keys))
+
+	for _, key := range keys {
+		v, ok := res.Set().Value(attribute.Key(key))
+		if !ok {
+			continue
+		}
+
+		m, err := baggage.NewMemberRaw(key, v.Emit())
+		if err != nil {
+			otel.Handle(err)
+
+			continue
+		}
+
+		members = append(members, m)
+	}
+
+	if len(members) == 0 {
+		return next
+	}
+
+	return &resourceBaggagePropagator{
+		members: members,
+		next:    next,
+	}
+}
+
+func (p *resourceBaggagePropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	bag := baggage.FromContext(ctx)
+
+	for _, m := range p.members {
+		// Values received from upstream services take precedence.
+		if bag.Member(m.Key()).Key() != "" {
+			continue
+		}
+
+		if b, err := bag.SetMember(m); err == nil {
+			bag = b
+		}
+	}
+
+	p.next.Inject(baggage.ContextWithBaggage(ctx, bag), carrier)
+}
+
+func (p *resourceBaggagePropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return p.next.Extract(ctx, carrier)
+}
+
+func (p *resourceBaggagePropagator) Fields() []string {
+	return p.next.Fields()
+}
+
+// withRequestBaggage returns context with the baggage entries of the request
+// added to the incoming baggage, so that they are propagated to the outgoing
+// requests. Entries with invalid keys or values are skipped.
+func withRequestBaggage(ctx context.Context, entries map[string]string) context.Context {
+	if len(entries) == 0 {
+		return ctx
+	}
+
+	bag := baggage.FromContext(ctx)
+
+	for k, v := range entries {
+		m, err := baggage.NewMemberRaw(k, v)
+		if err != nil {
+			otel.Handle(err)
+
+			continue
+		}
+
+		if b, err := bag.SetMember(m); err == nil {
+			bag = b
+		}
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag)
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestResourceBaggagePropagator(t *testing.T) {
+	res := resource.NewSchemaless(
+		semconv.DeploymentEnvironmentName("staging"),
+		semconv.ServiceName("orders"),
+	)
+
+	p := newResourceBaggagePropagator(res, []string{"deployment.environment.name", "missing"}, propagation.Baggage{})
+
+	carrier := propagation.MapCarrier{}
+	p.Inject(context.Background(), carrier)
+
+	qt.Check(t, qt.Equals(carrier.Get("baggage"), "deployment.environment.name=staging"))
+
+	m, err := baggage.NewMemberRaw("deployment.environment.name", "production")
+	qt.Assert(t, qt.IsNil(err))
+
+	bag, err := baggage.New(m)
+	qt.Assert(t, qt.IsNil(err))
+
+	carrier = propagation.MapCarrier{}
+	p.Inject(baggage.ContextWithBaggage(context.Background(), bag), carrier)
+
+	qt.Check(t, qt.Equals(carrier.Get("baggage"), "deployment.environment.name=production"))
+}
+
+func TestRequestBaggagePropagation(t *testing.T) {
+	p := newPropagator(&Configuration{}, nil)
+
+	ctx := oteltrace.ContextWithSpanContext(context.Background(), oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    oteltrace.TraceID{1},
+		SpanID:     oteltrace.SpanID{2},
+		TraceFlags: oteltrace.FlagsSampled,
+	}))
+	ctx = withRequestBaggage(ctx, map[string]string{"tenant.id": "acme", "invalid key": "x"})
+
+	carrier := propagation.MapCarrier{}
+	p.Inject(ctx, carrier)
+
+	qt.Check(t, qt.Equals(carrier.Get("traceparent"), "00-01000000000000000000000000000000-0200000000000000-01"))
+	qt.Check(t, qt.Equals(carrier.Get("baggage"), "tenant.id=acme"))
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"azugo.io/opentelemetry/telemetrybench"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func BenchmarkMessagingRecorder(b *testing.B) {
+	in := newInstrumentation(TracerProvider(telemetrybench.TracerProvider()))
+	ctx := context.Background()
+
+	telemetrybench.Run(b, func() {
+		in.record(ctx, InstrumentationMessagePublish, &MessagePublish{
+			System:      "kafka",
+			Destination: "orders",
+		})(nil)
+	})
+}
+
+func routeSamplingParameters() trace.SamplingParameters {
+	return trace.SamplingParameters{
+		ParentContext: context.Background(),
+		TraceID:       oteltrace.TraceID{1},
+		Name:          "GET /api/orders/{id}",
+		Kind:          oteltrace.SpanKindServer,
+		Attributes:    []attribute.KeyValue{semconv.HTTPRoute("/api/orders/{id}")},
+	}
+}
+
+func BenchmarkRouteSampler(b *testing.B) {
+	sampler := newRouteSampler(map[string]float64{
+		"/healthz": 0,
+		"/api/*":   0.5,
+	}, trace.AlwaysSample())
+	p := routeSamplingParameters()
+
+	telemetrybench.Run(b, func() {
+		sampler.ShouldSample(p)
+	})
+}
+
+func TestRouteSamplerAllocs(t *testing.T) {
+	sampler := newRouteSampler(map[string]float64{
+		"/healthz": 0,
+		"/api/*":   0.5,
+	}, trace.AlwaysSample())
+	p := routeSamplingParameters()
+
+	telemetrybench.AssertAllocs(t, 0, func() {
+		sampler.ShouldSample(p)
+	})
+}
+
+func BenchmarkAttributeScrubber(b *testing.B) {
+	s, err := newAttributeScrubber([]ScrubRule{
+		{Key: `^url\.full$`, Value: `[^/?&=]+@[^/?&=]+`, Action: ScrubActionMask},
+		{Key: `^http\.request\.header\.authorization$`},
+	}, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.URLFull("https://example.com/api/orders/1?x=1"),
+		semconv.HTTPRequestMethodGet,
+		semconv.HTTPRoute("/api/orders/{id}"),
+	}
+
+	telemetrybench.Run(b, func() {
+		s.scrub(attrs)
+	})
+}
@@ -4,15 +4,151 @@ package opentelemetry
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"azugo.io/core/cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
+var (
+	cacheOperationKey = attribute.Key("cache.operation")
+	cacheDurationKey  = attribute.Key("cache.duration")
+)
+
+// newCacheRecorder returns cache recorder that additionally records
+// cache operation duration metrics.
+func newCacheRecorder(meter metric.Meter, eventThreshold time.Duration) InstrumentationRecorderFunc {
+	duration, err := meter.Float64Histogram(
+		"cache.operation.duration",
+		metric.WithDescription("Duration of cache operations."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+
+		duration = metricnoop.Float64Histogram{}
+	}
+
+	record := cacheRecorder
+	if eventThreshold > 0 {
+		record = newCacheEventRecorder(eventThreshold)
+	}
+
+	return func(ctx context.Context, tr oteltrace.Tracer, propagator propagation.TextMapPropagator, spfmt InstrumentationSpanNameFormatter, op string, args ...any) (func(err error), bool) {
+		end, ok := record(ctx, tr, propagator, spfmt, op, args...)
+		if !ok {
+			return nil, false
+		}
+
+		start := time.Now()
+
+		return func(err error) {
+			attrs := make([]attribute.KeyValue, 0, 2)
+			attrs = append(attrs, cacheOperationKey.String(cacheOperation(op)))
+
+			if err != nil {
+				attrs = append(attrs, semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+			}
+
+			duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+
+			end(err)
+		}, true
+	}
+}
+
+func cacheOperation(op string) string {
+	switch op {
+	case cache.InstrumentationGet:
+		return "get"
+	case cache.InstrumentationSet:
+		return "set"
+	case cache.InstrumentationDelete:
+		return "delete"
+	default:
+		return op
+	}
+}
+
 func cacheRecorder(ctx context.Context, tr oteltrace.Tracer, _ propagation.TextMapPropagator, spfmt InstrumentationSpanNameFormatter, op string, args ...interface{}) (func(err error), bool) {
+	spanName, ok := cacheSpanName(ctx, spfmt, op, args...)
+	if !ok {
+		return nil, false
+	}
+
+	//nolint:spancheck
+	_, span := tr.Start(FromContext(ctx), spanName, cacheSpanOptions()...)
+
+	//nolint:spancheck
+	return func(err error) {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+
+			span.RecordError(err, oteltrace.WithStackTrace(true))
+		}
+
+		span.End()
+	}, true
+}
+
+// newCacheEventRecorder returns cache recorder that records successful operations
+// faster than the threshold as events on the parent span instead of child spans.
+func newCacheEventRecorder(threshold time.Duration) InstrumentationRecorderFunc {
+	return func(ctx context.Context, tr oteltrace.Tracer, _ propagation.TextMapPropagator, spfmt InstrumentationSpanNameFormatter, op string, args ...any) (func(err error), bool) {
+		spanName, ok := cacheSpanName(ctx, spfmt, op, args...)
+		if !ok {
+			return nil, false
+		}
+
+		return cacheEventEnd(FromContext(ctx), tr, threshold, spanName, op), true
+	}
+}
+
+// cacheEventEnd starts timing cache operation and returns function to be called
+// when it ends, that records it as an event on the parent span in the context
+// or as a child span if it failed or was slower than the threshold.
+func cacheEventEnd(c context.Context, tr oteltrace.Tracer, threshold time.Duration, spanName, op string) func(err error) {
+	start := time.Now()
+
+	return func(err error) {
+		end := time.Now()
+
+		if err == nil && end.Sub(start) < threshold {
+			if parent := oteltrace.SpanFromContext(c); parent.IsRecording() {
+				parent.AddEvent(spanName,
+					oteltrace.WithTimestamp(start),
+					oteltrace.WithAttributes(
+						cacheOperationKey.String(cacheOperation(op)),
+						cacheDurationKey.Float64(end.Sub(start).Seconds()),
+					),
+				)
+			}
+
+			return
+		}
+
+		// Span is started when the operation ends as it is only known then if it is slow.
+		_, span := tr.Start(c, spanName, append(cacheSpanOptions(), oteltrace.WithTimestamp(start))...)
+
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+
+			span.RecordError(err, oteltrace.WithStackTrace(true))
+		}
+
+		span.End(oteltrace.WithTimestamp(end))
+	}
+}
+
+func cacheSpanName(ctx context.Context, spfmt InstrumentationSpanNameFormatter, op string, args ...any) (string, bool) {
 	var (
 		name   string
 		method string
@@ -23,53 +159,41 @@ func cacheRecorder(ctx context.Context, tr oteltrace.Tracer, _ propagation.TextM
 	case cache.InstrumentationGet:
 		name, ok = cache.InstrGet(op, args...)
 		if !ok {
-			return nil, false
+			return "", false
 		}
 
 		method = "GET "
 	case cache.InstrumentationSet:
 		name, ok = cache.InstrSet(op, args...)
 		if !ok {
-			return nil, false
+			return "", false
 		}
 
 		method = "SET "
 	case cache.InstrumentationDelete:
 		name, ok = cache.InstrDelete(op, args...)
 		if !ok {
-			return nil, false
+			return "", false
 		}
 
 		method = "DELETE "
 	default:
-		return nil, false
+		return "", false
 	}
 
-	c := FromContext(ctx)
-
 	spanName := spfmt(ctx, op, args...)
 	if spanName == "" {
 		spanName = method + name
 	}
 
-	opts := []oteltrace.SpanStartOption{
+	return spanName, true
+}
+
+func cacheSpanOptions() []oteltrace.SpanStartOption {
+	return []oteltrace.SpanStartOption{
 		oteltrace.WithAttributes(
 			semconv.PeerService("cache"),
 		),
 		oteltrace.WithSpanKind(oteltrace.SpanKindInternal),
 	}
-
-	//nolint:spancheck
-	_, span := tr.Start(c, spanName, opts...)
-
-	//nolint:spancheck
-	return func(err error) {
-		if err != nil {
-			span.SetStatus(codes.Error, err.Error())
-
-			span.RecordError(err, oteltrace.WithStackTrace(true))
-		}
-
-		span.End()
-	}, true
 }
@@ -0,0 +1,54 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"azugo.io/core/cache"
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCacheEventEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := trace.NewTracerProvider(trace.WithSpanProcessor(recorder)).Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "GET /users/{id}")
+
+	// Fast operation is recorded as an event on the parent span.
+	cacheEventEnd(ctx, tracer, time.Hour, "GET user:1", cache.InstrumentationGet)(nil)
+
+	// Slow operation is recorded as a child span started before the operation.
+	end := cacheEventEnd(ctx, tracer, time.Millisecond, "GET user:2", cache.InstrumentationGet)
+	started := time.Now()
+
+	time.Sleep(2 * time.Millisecond)
+	end(nil)
+
+	// Failed operation is recorded as a child span regardless of its duration.
+	cacheEventEnd(ctx, tracer, time.Hour, "GET user:3", cache.InstrumentationGet)(errors.New("connection refused"))
+
+	parent.End()
+
+	spans := recorder.Ended()
+	qt.Assert(t, qt.HasLen(spans, 3))
+
+	qt.Check(t, qt.Equals(spans[0].Name(), "GET user:2"))
+	qt.Check(t, qt.IsFalse(spans[0].StartTime().After(started)))
+	qt.Check(t, qt.IsTrue(spans[0].EndTime().Sub(spans[0].StartTime()) >= 2*time.Millisecond))
+	qt.Check(t, qt.Equals(spans[0].Parent().SpanID(), parent.SpanContext().SpanID()))
+
+	qt.Check(t, qt.Equals(spans[1].Name(), "GET user:3"))
+	qt.Check(t, qt.HasLen(spans[1].Events(), 1))
+
+	events := spans[2].Events()
+	qt.Assert(t, qt.HasLen(events, 1))
+	qt.Check(t, qt.Equals(events[0].Name, "GET user:1"))
+	qt.Check(t, qt.Equals(events[0].Attributes[0], cacheOperationKey.String("get")))
+}
@@ -0,0 +1,203 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"azugo.io/azugo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.uber.org/zap"
+)
+
+// CardinalityOverflowValue replaces attribute values that exceed the configured
+// cardinality limit.
+const CardinalityOverflowValue = "_OTHER"
+
+// DefaultCardinalityKeys are the attribute keys guarded by CardinalityLimit
+// when no keys are specified.
+var DefaultCardinalityKeys = []attribute.Key{
+	semconv.HTTPRouteKey,
+	semconv.PeerServiceKey,
+	semconv.ServerAddressKey,
+	tenantIDKey,
+}
+
+// CardinalityLimit configures the instrumentation to track unique values observed
+// for the given attribute keys on the recorded spans and metrics. When the number
+// of unique values for a key exceeds the limit, any new values are replaced
+// with CardinalityOverflowValue, warning is logged once and overflow is counted
+// by "azugo.telemetry.cardinality.overflows" metric.
+//
+// If no keys are specified, DefaultCardinalityKeys are used.
+func CardinalityLimit(limit int, keys ...attribute.Key) Option {
+	if len(keys) == 0 {
+		keys = DefaultCardinalityKeys
+	}
+
+	// Guard is shared by all instrumentation created with this option.
+	g := newCardinalityGuard(limit, keys)
+
+	return optionFunc(func(cfg *otelcfg) {
+		cfg.cardinality = g
+	})
+}
+
+type cardinalityValues struct {
+	mu     sync.Mutex
+	values map[string]struct{}
+	warned bool
+}
+
+// cardinalityGuard limits number of unique values for the attribute keys.
+type cardinalityGuard struct {
+	limit int
+	keys  map[attribute.Key]*cardinalityValues
+
+	once      sync.Once
+	overflows metric.Int64Counter
+}
+
+func newCardinalityGuard(limit int, keys []attribute.Key) *cardinalityGuard {
+	g := &cardinalityGuard{
+		limit: limit,
+		keys:  make(map[attribute.Key]*cardinalityValues, len(keys)),
+	}
+
+	for _, k := range keys {
+		g.keys[k] = &cardinalityValues{
+			values: make(map[string]struct{}),
+		}
+	}
+
+	return g
+}
+
+// init creates metric instruments for reporting on the first use.
+func (g *cardinalityGuard) init(mp metric.MeterProvider) {
+	if g == nil {
+		return
+	}
+
+	g.once.Do(func() {
+		meter := mp.Meter(
+			ScopeName,
+			metric.WithInstrumentationVersion(Version()),
+			metric.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+		)
+
+		var err error
+
+		g.overflows, err = meter.Int64Counter(
+			"azugo.telemetry.cardinality.overflows",
+			metric.WithDescription("Number of attribute values replaced because the cardinality limit was exceeded."),
+			metric.WithUnit("{value}"),
+		)
+		if err != nil {
+			otel.Handle(err)
+		}
+
+		_, err = meter.Int64ObservableGauge(
+			"azugo.telemetry.cardinality",
+			metric.WithDescription("Number of unique values observed for the guarded attribute keys."),
+			metric.WithUnit("{value}"),
+			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+				for k, v := range g.keys {
+					v.mu.Lock()
+					n := len(v.values)
+					v.mu.Unlock()
+
+					o.Observe(int64(n), metric.WithAttributes(attribute.String("attribute.key", string(k))))
+				}
+
+				return nil
+			}),
+		)
+		if err != nil {
+			otel.Handle(err)
+		}
+	})
+}
+
+// filter returns attributes with values exceeding the cardinality limit replaced.
+// Provided slice is never modified.
+func (g *cardinalityGuard) filter(ctx context.Context, attrs []attribute.KeyValue) []attribute.KeyValue {
+	if g == nil {
+		return attrs
+	}
+
+	var filtered []attribute.KeyValue
+
+	for i, kv := range attrs {
+		v, ok := g.keys[kv.Key]
+		if !ok || v.allow(kv.Value.Emit(), g.limit) {
+			continue
+		}
+
+		if filtered == nil {
+			filtered = make([]attribute.KeyValue, len(attrs))
+			copy(filtered, attrs)
+		}
+
+		filtered[i] = kv.Key.String(CardinalityOverflowValue)
+
+		g.report(ctx, kv.Key, v)
+	}
+
+	if filtered == nil {
+		return attrs
+	}
+
+	return filtered
+}
+
+func (v *cardinalityValues) allow(val string, limit int) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, ok := v.values[val]; ok {
+		return true
+	}
+
+	if len(v.values) >= limit {
+		return false
+	}
+
+	v.values[val] = struct{}{}
+
+	return true
+}
+
+func (g *cardinalityGuard) report(ctx context.Context, key attribute.Key, v *cardinalityValues) {
+	attrKey := attribute.String("attribute.key", string(key))
+
+	if g.overflows != nil {
+		g.overflows.Add(ctx, 1, metric.WithAttributes(attrKey))
+	}
+
+	v.mu.Lock()
+	warned := v.warned
+	v.warned = true
+	v.mu.Unlock()
+
+	if warned {
+		return
+	}
+
+	if c := azugo.RequestContext(ctx); c != nil {
+		c.Log().Warn("Attribute cardinality limit exceeded",
+			zap.String("attribute.key", string(key)),
+			zap.Int("limit", g.limit),
+		)
+
+		return
+	}
+
+	otel.Handle(fmt.Errorf("attribute %q cardinality limit of %d exceeded", key, g.limit))
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
+func TestCardinalityGuard(t *testing.T) {
+	g := newCardinalityGuard(2, []attribute.Key{semconv.HTTPRouteKey})
+	ctx := context.Background()
+
+	for _, route := range []string{"/a", "/b", "/a"} {
+		attrs := g.filter(ctx, []attribute.KeyValue{semconv.HTTPRoute(route)})
+		qt.Check(t, qt.Equals(attrs[0], semconv.HTTPRoute(route)))
+	}
+
+	in := []attribute.KeyValue{semconv.HTTPRoute("/c"), semconv.HTTPRequestMethodGet}
+	attrs := g.filter(ctx, in)
+	qt.Check(t, qt.Equals(attrs[0], semconv.HTTPRoute(CardinalityOverflowValue)))
+	qt.Check(t, qt.Equals(attrs[1], semconv.HTTPRequestMethodGet))
+	qt.Check(t, qt.Equals(in[0], semconv.HTTPRoute("/c")))
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"azugo.io/azugo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const (
+	// ClaimRedactHash replaces claim value with truncated HMAC-SHA256 of it
+	// keyed with the configured redact hash key.
+	ClaimRedactHash = "hash"
+	// ClaimRedactMask replaces claim value with a fixed mask.
+	ClaimRedactMask = "mask"
+)
+
+const redactedClaimValue = "****"
+
+// processHashKey is the random key used to hash values when no redact hash key
+// is configured, so hashes can not be reversed by brute-forcing low entropy
+// values but are comparable only within the process.
+var processHashKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+
+	return key
+})
+
+// redactHashKey returns configured key for hashing redacted values.
+func redactHashKey(config *Configuration) []byte {
+	if config.RedactHashKey == "" {
+		return processHashKey()
+	}
+
+	return []byte(config.RedactHashKey)
+}
+
+type claimMapping struct {
+	claim  string
+	key    attribute.Key
+	redact string
+}
+
+// claimEnricher adds attributes from the authorized user claims.
+type claimEnricher struct {
+	value    UserClaim
+	mappings []claimMapping
+	hashKey  []byte
+}
+
+func newClaimEnricher(config *Configuration, value UserClaim) *claimEnricher {
+	if value == nil || len(config.ClaimAttributes) == 0 {
+		return nil
+	}
+
+	mappings := make([]claimMapping, 0, len(config.ClaimAttributes))
+	for claim, key := range config.ClaimAttributes {
+		mappings = append(mappings, claimMapping{
+			claim:  claim,
+			key:    attribute.Key(key),
+			redact: config.RedactClaims[claim],
+		})
+	}
+
+	return &claimEnricher{
+		value:    value,
+		mappings: mappings,
+		hashKey:  redactHashKey(config),
+	}
+}
+
+func (e *claimEnricher) attributes(ctx *azugo.Context) []attribute.KeyValue {
+	user := ctx.User()
+	if user == nil || !user.Authorized() {
+		return nil
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(e.mappings))
+
+	for _, m := range e.mappings {
+		claim := e.value(user, m.claim)
+		if len(claim) == 0 {
+			continue
+		}
+
+		vals := make([]string, 0, len(claim))
+		for _, v := range claim {
+			vals = append(vals, redactClaim(e.hashKey, m.redact, v))
+		}
+
+		if len(vals) == 1 {
+			attrs = append(attrs, m.key.String(vals[0]))
+		} else {
+			attrs = append(attrs, m.key.StringSlice(vals))
+		}
+	}
+
+	return attrs
+}
+
+// logFields returns claim attributes as log fields.
+func (e *claimEnricher) logFields(ctx *azugo.Context) []zap.Field {
+	attrs := e.attributes(ctx)
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	fields := make([]zap.Field, 0, len(attrs))
+
+	for _, kv := range attrs {
+		if kv.Value.Type() == attribute.STRINGSLICE {
+			fields = append(fields, zap.Strings(string(kv.Key), kv.Value.AsStringSlice()))
+		} else {
+			fields = append(fields, zap.String(string(kv.Key), kv.Value.AsString()))
+		}
+	}
+
+	return fields
+}
+
+func redactClaim(key []byte, mode, value string) string {
+	switch mode {
+	case ClaimRedactHash:
+		h := hmac.New(sha256.New, key)
+		_, _ = h.Write([]byte(value))
+
+		return hex.EncodeToString(h.Sum(nil)[:8])
+	case ClaimRedactMask:
+		return redactedClaimValue
+	default:
+		return value
+	}
+}
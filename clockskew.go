@@ -0,0 +1,98 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ClockOffset configures function returning the offset added to the timestamps
+// of the exported spans to correct the host clock skew, for example measured
+// from the NTP status. It takes precedence over clock_offset configuration.
+// The function is called for each ended span so it must be fast.
+func ClockOffset(offset func() time.Duration) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		cfg.clockOffset = offset
+	})
+}
+
+// clockOffset returns clock offset function from the options or configuration.
+func clockOffset(cfg *otelcfg, config *Configuration) func() time.Duration {
+	if cfg.clockOffset != nil {
+		return cfg.clockOffset
+	}
+
+	if config.ClockOffset != 0 {
+		offset := config.ClockOffset
+
+		return func() time.Duration {
+			return offset
+		}
+	}
+
+	return nil
+}
+
+type clockSkewSpanProcessor struct {
+	trace.SpanProcessor
+
+	offset func() time.Duration
+}
+
+func newClockSkewSpanProcessor(p trace.SpanProcessor, offset func() time.Duration) trace.SpanProcessor {
+	if offset == nil {
+		return p
+	}
+
+	return &clockSkewSpanProcessor{
+		SpanProcessor: p,
+		offset:        offset,
+	}
+}
+
+func (p *clockSkewSpanProcessor) OnEnd(s trace.ReadOnlySpan) {
+	offset := p.offset()
+	if offset == 0 {
+		p.SpanProcessor.OnEnd(s)
+
+		return
+	}
+
+	p.SpanProcessor.OnEnd(&skewedSpan{
+		ReadOnlySpan: s,
+		offset:       offset,
+	})
+}
+
+// skewedSpan is a read-only span view with timestamps corrected by the clock offset.
+type skewedSpan struct {
+	trace.ReadOnlySpan
+
+	offset time.Duration
+}
+
+func (s *skewedSpan) StartTime() time.Time {
+	return s.ReadOnlySpan.StartTime().Add(s.offset)
+}
+
+func (s *skewedSpan) EndTime() time.Time {
+	return s.ReadOnlySpan.EndTime().Add(s.offset)
+}
+
+func (s *skewedSpan) Events() []trace.Event {
+	events := s.ReadOnlySpan.Events()
+	if len(events) == 0 {
+		return events
+	}
+
+	skewed := make([]trace.Event, len(events))
+	for i, e := range events {
+		e.Time = e.Time.Add(s.offset)
+		skewed[i] = e
+	}
+
+	return skewed
+}
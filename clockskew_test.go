@@ -0,0 +1,35 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestClockSkewSpanProcessor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(newClockSkewSpanProcessor(recorder, func() time.Duration {
+		return 2 * time.Second
+	})))
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, span := tp.Tracer("test").Start(context.Background(), "test", oteltrace.WithTimestamp(start))
+	span.AddEvent("event", oteltrace.WithTimestamp(start.Add(time.Second)))
+	span.End(oteltrace.WithTimestamp(start.Add(3 * time.Second)))
+
+	spans := recorder.Ended()
+	qt.Assert(t, qt.HasLen(spans, 1))
+	qt.Check(t, qt.Equals(spans[0].StartTime(), start.Add(2*time.Second)))
+	qt.Check(t, qt.Equals(spans[0].EndTime(), start.Add(5*time.Second)))
+	qt.Check(t, qt.Equals(spans[0].Events()[0].Time, start.Add(3*time.Second)))
+}
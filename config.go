@@ -5,19 +5,297 @@ package opentelemetry
 
 import (
 	"os"
+	"strings"
+	"time"
 
 	"azugo.io/core/config"
 	"azugo.io/core/validation"
 	"github.com/spf13/viper"
 )
 
+const (
+	// ExporterOTLP exports telemetry to the OpenTelemetry collector using OTLP protocol.
+	ExporterOTLP = "otlp"
+	// ExporterConsole writes telemetry to the standard output, useful for local development.
+	ExporterConsole = "console"
+	// ExporterNone disables exporting of the telemetry.
+	ExporterNone = "none"
+)
+
+// DefaultShutdownTimeout is the default time limit for flushing pending
+// telemetry on shutdown.
+const DefaultShutdownTimeout = 5 * time.Second
+
 // Configuration section for OpenTracing.
 type Configuration struct {
-	Disabled              bool   `mapstructure:"disabled"`
+	Disabled              bool       `mapstructure:"disabled"`
+	Exporter              string     `mapstructure:"exporter" validate:"omitempty,oneof=otlp console none"`
+	Endpoint              string     `mapstructure:"endpoint"`
+	InsecureSkipVerify    bool       `mapstructure:"insecure_skip_verify"`
+	TLS                   TLS        `mapstructure:"tls"`
+	ServiceName           string     `mapstructure:"service_name"`
+	ElasticAPMSecretToken string     `mapstructure:"elastic_apm_secret_token"`
+	ElasticAPMAPIKey      string     `mapstructure:"elastic_apm_api_key"`
+	Compression           string     `mapstructure:"compression" validate:"omitempty,oneof=gzip none"`
+	SpanLimits            SpanLimits `mapstructure:"span_limits"`
+	// IgnorePaths lists path prefixes of requests that are not traced. Unlike SkipPaths, trace
+	// context is still propagated for them if FilteredPropagation option is enabled.
+	IgnorePaths []string `mapstructure:"ignore_paths"`
+	// ClaimAttributes maps authorized user claim names to span attribute keys.
+	ClaimAttributes map[string]string `mapstructure:"claim_attributes"`
+	// RedactClaims maps claim names to redaction mode: "hash" or "mask".
+	RedactClaims map[string]string `mapstructure:"redact_claims" validate:"dive,oneof=hash mask"`
+	// RedactHashKey is the secret key of HMAC used to hash redacted claims and scrubbed attributes.
+	// Random key is generated on startup if not set, so hashes are comparable only within the process.
+	RedactHashKey string `mapstructure:"redact_hash_key"`
+	// ResourceDetectors lists cloud resource detectors to run on startup: "ec2", "ecs", "eks", "gcp" or "azure".
+	ResourceDetectors []string `mapstructure:"resource_detectors" validate:"dive,oneof=ec2 ecs eks gcp azure"`
+	// ResourceAttributes is a comma-separated list of key=value pairs added to the resource.
+	ResourceAttributes string `mapstructure:"resource_attributes"`
+	// Timeout of the single export request. Zero value means exporter default (10s).
+	Timeout time.Duration `mapstructure:"timeout" validate:"min=0"`
+	// ScrubAttributes rules remove or hash sensitive span attributes before export.
+	ScrubAttributes []ScrubRule `mapstructure:"scrub_attributes" validate:"dive"`
+	// IDGenerator is the trace ID generator: "random" (default) or "xray" that also enables AWS X-Ray propagation.
+	IDGenerator string `mapstructure:"id_generator" validate:"omitempty,oneof=random xray"`
+	// RouteSampling maps route patterns to the ratio of traces to sample. Patterns are matched
+	// using path.Match against route template, exact and longer patterns take precedence.
+	RouteSampling map[string]float64 `mapstructure:"route_sampling" validate:"dive,min=0,max=1"`
+	// Headers are additional HTTP headers sent with each export request.
+	Headers map[string]string `mapstructure:"headers"`
+	// AgentDiscovery enables looking for node-local collector agent when endpoint is not configured.
+	AgentDiscovery bool `mapstructure:"agent_discovery"`
+	// ShutdownTimeout limits time spent flushing pending telemetry on shutdown.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" validate:"min=0"`
+	// BaggageResourceAttributes lists resource attribute keys, for example "deployment.environment.name",
+	// to propagate as baggage to downstream services.
+	BaggageResourceAttributes []string `mapstructure:"baggage_resource_attributes"`
+	// PanicGoroutineDump attaches truncated dump of all goroutines to the server span on panic.
+	PanicGoroutineDump bool `mapstructure:"panic_goroutine_dump"`
+	// ClockOffset is added to the timestamps of the exported spans to correct the host clock skew.
+	ClockOffset time.Duration `mapstructure:"clock_offset"`
+	// PeerServiceMap maps outgoing HTTP request host (with or without port) to "peer.service" attribute value.
+	PeerServiceMap map[string]string `mapstructure:"peer_service_map"`
+	// SpanAttributes are added to every server span, for example "service.team" or "cost.center"
+	// to filter spans in the backend.
+	SpanAttributes map[string]string `mapstructure:"span_attributes"`
+	// SkipPaths lists request paths or path.Match patterns, for example Kubernetes probes, that never
+	// create spans or propagate trace context. Unlike IgnorePaths, paths are not matched as prefixes.
+	SkipPaths []string `mapstructure:"skip_paths"`
+	// SkipUserAgents lists user agent prefixes, for example "kube-probe/" or "ELB-HealthChecker/",
+	// of requests that never create spans or propagate trace context.
+	SkipUserAgents []string `mapstructure:"skip_user_agents"`
+
+	DeploymentEnvironment DeploymentEnvironment `mapstructure:"deployment_environment"`
+	Retry                 Retry                 `mapstructure:"retry"`
+	AdditionalEndpoints   []ExporterEndpoint    `mapstructure:"additional_endpoints" validate:"dive"`
+	RouteExporters        []RouteExporter       `mapstructure:"route_exporters" validate:"dive"`
+	Migration             Migration             `mapstructure:"migration"`
+	TenantSampling        TenantSampling        `mapstructure:"tenant_sampling"`
+	TailSampling          TailSampling          `mapstructure:"tail_sampling"`
+	Metrics               Metrics               `mapstructure:"metrics"`
+	NPlusOne              NPlusOne              `mapstructure:"n_plus_one"`
+
+	envs envBindings
+}
+
+// NPlusOne configuration section for collapsing many sibling spans with the same
+// name (for example identical cache or database calls made in a loop) into a single
+// summary span with "nplusone.count" and "nplusone.duration" attributes flagged
+// with "nplusone.detected" attribute.
+//
+// Sampled spans are kept in memory until the local root span of the trace ends.
+type NPlusOne struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Threshold is the number of sibling spans with the same name to collapse. Zero value means default of 10.
+	Threshold int `mapstructure:"threshold" validate:"min=0"`
+	// MaxBufferedSpans limits number of spans kept in memory. Zero value means default limit of 10000.
+	MaxBufferedSpans int `mapstructure:"max_buffered_spans" validate:"min=0"`
+}
+
+// Metrics configuration section for the metrics recorded by the instrumentation.
+type Metrics struct {
+	// DurationBuckets are explicit bucket boundaries in seconds of the HTTP server
+	// and client request duration histograms. Empty value means SDK defaults.
+	DurationBuckets []float64 `mapstructure:"duration_buckets" validate:"dive,min=0"`
+}
+
+// TailSampling configuration section for always exporting traces of failed
+// and slow requests while sampling the rest.
+//
+// All spans are recorded and spans of not sampled traces are kept in memory
+// until the local root span ends to decide whether to export them.
+type TailSampling struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Ratio of traces to sample regardless of the outcome.
+	Ratio float64 `mapstructure:"ratio" validate:"min=0,max=1"`
+	// LatencyThreshold above which traces are always sampled. Zero value disables latency check.
+	LatencyThreshold time.Duration `mapstructure:"latency_threshold" validate:"min=0"`
+	// MaxBufferedSpans limits number of spans kept in memory. Zero value means default limit of 10000.
+	MaxBufferedSpans int `mapstructure:"max_buffered_spans" validate:"min=0"`
+}
+
+// TenantSampling configuration section for overriding sampling rate of the traces
+// started by requests of specific tenants.
+//
+// Tenant is resolved from the user claim if the user is authorized before the
+// tracing middleware or otherwise from the request header. Requests with incoming
+// sampled trace context always follow the parent sampling decision.
+type TenantSampling struct {
+	// Header to read tenant identifier from. Clients can set any value, so the header
+	// must be set (or stripped) by the trusted edge proxy or gateway.
+	Header string `mapstructure:"header"`
+	// Claim to read tenant identifier from. Requires UserClaim option to be provided.
+	Claim string `mapstructure:"claim"`
+	// Rates maps tenant identifiers to the ratio of traces to sample.
+	Rates map[string]float64 `mapstructure:"rates" validate:"dive,min=0,max=1"`
+	// DefaultRate of traces to sample for tenants not listed in rates. Zero value means
+	// the same rate as for requests without tenant.
+	DefaultRate float64 `mapstructure:"default_rate" validate:"min=0,max=1"`
+}
+
+// ScrubRule configuration section for removing or hashing sensitive attributes
+// (for example emails or card numbers in "url.full") before export.
+type ScrubRule struct {
+	// Key is a regular expression matching attribute keys. Empty value matches all keys.
+	Key string `mapstructure:"key"`
+	// Value is a regular expression matching string attribute values. Empty value matches any value.
+	Value string `mapstructure:"value"`
+	// Action is "remove" (default), "hash" to replace value with its hash or "mask" to replace
+	// matching part of the value with a fixed mask.
+	Action string `mapstructure:"action" validate:"omitempty,oneof=remove hash mask"`
+}
+
+// TLS configuration section for the connection to the OpenTelemetry server.
+type TLS struct {
+	// CAFile is the path to the CA certificate file used to verify server certificate instead of system roots.
+	CAFile string `mapstructure:"ca_file" validate:"omitempty,file"`
+	// CertFile is the path to the client certificate file for mutual TLS.
+	CertFile string `mapstructure:"cert_file" validate:"required_with=KeyFile,omitempty,file"`
+	// KeyFile is the path to the client private key file for mutual TLS.
+	KeyFile string `mapstructure:"key_file" validate:"required_with=CertFile,omitempty,file"`
+	// ServerNameOverride is the server name used to verify server certificate instead of the endpoint host.
+	ServerNameOverride string `mapstructure:"server_name_override"`
+	// MinVersion is the minimum TLS version: "1.0", "1.1", "1.2" (default) or "1.3".
+	MinVersion string `mapstructure:"min_version" validate:"omitempty,oneof=1.0 1.1 1.2 1.3"`
+	// InsecureSkipVerify disables server certificate verification. Supersedes
+	// "insecure_skip_verify" key of the main section that is kept for compatibility.
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+}
+
+// Retry configuration section for retrying failed exports.
+//
+// Zero values mean exporter defaults are used.
+type Retry struct {
+	Disabled bool `mapstructure:"disabled"`
+	// InitialInterval to wait after the first failure before retrying.
+	InitialInterval time.Duration `mapstructure:"initial_interval" validate:"min=0"`
+	// MaxInterval is the upper bound on backoff interval.
+	MaxInterval time.Duration `mapstructure:"max_interval" validate:"min=0"`
+	// MaxElapsedTime is the maximum amount of time spent trying to send a batch.
+	MaxElapsedTime time.Duration `mapstructure:"max_elapsed_time" validate:"min=0"`
+}
+
+// ExporterEndpoint configuration section for additional OTLP endpoint all
+// traces are exported to, for example a vendor endpoint besides the local collector.
+//
+// Other exporter settings are shared with the primary endpoint.
+type ExporterEndpoint struct {
+	Endpoint              string            `mapstructure:"endpoint" validate:"required,url"`
+	Headers               map[string]string `mapstructure:"headers"`
+	InsecureSkipVerify    bool              `mapstructure:"insecure_skip_verify"`
+	ElasticAPMSecretToken string            `mapstructure:"elastic_apm_secret_token"`
+	ElasticAPMAPIKey      string            `mapstructure:"elastic_apm_api_key"`
+}
+
+// RouteExporter configuration section for exporting traces of requests matching
+// the routes to a different endpoint instead of the primary one, for example to
+// a separate Elastic APM project of the product.
+//
+// Other exporter settings are shared with the primary endpoint.
+type RouteExporter struct {
+	// Routes are route templates or patterns matched using path.Match. Patterns ending
+	// with "/*" match all routes with the prefix, for example "/partner-api/*".
+	Routes []string `mapstructure:"routes" validate:"required,min=1"`
+
+	ExporterEndpoint `mapstructure:",squash"`
+}
+
+// Migration configuration section for shipping traces to the second backend
+// at the same time, for example while evaluating or migrating to a new one.
+//
+// Other exporter settings are shared with the primary backend.
+type Migration struct {
 	Endpoint              string `mapstructure:"endpoint"`
 	InsecureSkipVerify    bool   `mapstructure:"insecure_skip_verify"`
-	ServiceName           string `mapstructure:"service_name"`
 	ElasticAPMSecretToken string `mapstructure:"elastic_apm_secret_token"`
+	ElasticAPMAPIKey      string `mapstructure:"elastic_apm_api_key"`
+	// Ratio of traces to export to the migration backend. All traces are exported if not set,
+	// zero value disables exporting to the migration backend.
+	Ratio *float64 `mapstructure:"ratio" validate:"omitempty,min=0,max=1"`
+	// PrimaryRatio of traces to export to the primary backend. All traces are exported if not set,
+	// zero value disables exporting to the primary backend.
+	PrimaryRatio *float64 `mapstructure:"primary_ratio" validate:"omitempty,min=0,max=1"`
+}
+
+const (
+	// CompressionGzip compresses exported telemetry using gzip.
+	CompressionGzip = "gzip"
+	// CompressionNone sends exported telemetry uncompressed.
+	CompressionNone = "none"
+)
+
+const (
+	// DeploymentEnvironmentSourceApp uses Azugo application environment.
+	DeploymentEnvironmentSourceApp = "app"
+	// DeploymentEnvironmentSourceEnv reads environment name from the environment variable.
+	DeploymentEnvironmentSourceEnv = "env"
+	// DeploymentEnvironmentSourceConfig uses environment name set in the configuration.
+	DeploymentEnvironmentSourceConfig = "config"
+)
+
+const (
+	// DeploymentEnvironmentCasingLower converts environment name to lower case.
+	DeploymentEnvironmentCasingLower = "lower"
+	// DeploymentEnvironmentCasingUpper converts environment name to upper case.
+	DeploymentEnvironmentCasingUpper = "upper"
+	// DeploymentEnvironmentCasingNone keeps environment name as is.
+	DeploymentEnvironmentCasingNone = "none"
+)
+
+// DeploymentEnvironment configuration section for the "deployment.environment.name"
+// resource attribute.
+type DeploymentEnvironment struct {
+	// Source of the environment name: "app" (default), "env" or "config".
+	Source string `mapstructure:"source" validate:"omitempty,oneof=app env config"`
+	// EnvVar is the name of environment variable to read environment name from when source is "env".
+	EnvVar string `mapstructure:"env_var"`
+	// Name is the environment name to use when source is "config".
+	Name string `mapstructure:"name"`
+	// Casing of the environment name: "lower" (default), "upper" or "none".
+	Casing string `mapstructure:"casing" validate:"omitempty,oneof=lower upper none"`
+}
+
+// SpanLimits configuration section for limits applied to recorded spans.
+//
+// Zero values are ignored and OpenTelemetry SDK defaults are used instead.
+// Negative values mean that there is no limit.
+type SpanLimits struct {
+	AttributeValueLengthLimit   int `mapstructure:"attribute_value_length_limit"`
+	AttributeCountLimit         int `mapstructure:"attribute_count_limit"`
+	EventCountLimit             int `mapstructure:"event_count_limit"`
+	LinkCountLimit              int `mapstructure:"link_count_limit"`
+	AttributePerEventCountLimit int `mapstructure:"attribute_per_event_count_limit"`
+	AttributePerLinkCountLimit  int `mapstructure:"attribute_per_link_count_limit"`
+}
+
+// ratioOrAll returns the ratio value or 1 if it is not set.
+func ratioOrAll(r *float64) float64 {
+	if r == nil {
+		return 1
+	}
+
+	return *r
 }
 
 // Validate OpenTracing configuration section.
@@ -28,19 +306,116 @@ func (c *Configuration) Validate(valid *validation.Validate) error {
 // Bind OpenTracing configuration section.
 func (c *Configuration) Bind(prefix string, v *viper.Viper) {
 	st, _ := config.LoadRemoteSecret("ELASTIC_APM_SECRET_TOKEN")
+	ak, _ := config.LoadRemoteSecret("ELASTIC_APM_API_KEY")
 
 	v.SetDefault(prefix+".disabled", false)
+	v.SetDefault(prefix+".exporter", ExporterOTLP)
 	v.SetDefault(prefix+".insecure_skip_verify", false)
 	v.SetDefault(prefix+".elastic_apm_secret_token", st)
+	v.SetDefault(prefix+".elastic_apm_api_key", ak)
+	v.SetDefault(prefix+".shutdown_timeout", DefaultShutdownTimeout)
+
+	c.envs = make(envBindings)
+
+	c.envs.bind(v, prefix+".disabled", "OTEL_SDK_DISABLED")
+	c.envs.bind(v, prefix+".exporter", "OTEL_TRACES_EXPORTER")
+	c.envs.bind(v, prefix+".endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", "ELASTIC_APM_SERVER_URL")
+	c.envs.bind(v, prefix+".agent_discovery", "OTEL_EXPORTER_OTLP_AGENT_DISCOVERY")
+	c.envs.bind(v, prefix+".insecure_skip_verify", "OTEL_EXPORTER_OTLP_INSECURE_SKIP_VERIFY")
+	c.envs.bind(v, prefix+".service_name", "OTEL_SERVICE_NAME", "ELASTIC_APM_SERVICE_NAME")
+	c.envs.bind(v, prefix+".compression", "OTEL_EXPORTER_OTLP_TRACES_COMPRESSION", "OTEL_EXPORTER_OTLP_COMPRESSION")
+	c.envs.bind(v, prefix+".elastic_apm_secret_token", "ELASTIC_APM_SECRET_TOKEN")
+	c.envs.bind(v, prefix+".elastic_apm_api_key", "ELASTIC_APM_API_KEY")
+	c.envs.bind(v, prefix+".resource_detectors", "OTEL_RESOURCE_DETECTORS")
+	c.envs.bind(v, prefix+".resource_attributes", "OTEL_RESOURCE_ATTRIBUTES")
+
+	c.TLS.bind(prefix+".tls", v, c.envs)
+	c.SpanLimits.bind(prefix+".span_limits", v, c.envs)
+	c.DeploymentEnvironment.bind(prefix+".deployment_environment", v, c.envs)
+}
+
+// Bind TLS configuration section.
+func (c *TLS) Bind(prefix string, v *viper.Viper) {
+	c.bind(prefix, v, nil)
+}
+
+func (c *TLS) bind(prefix string, v *viper.Viper, envs envBindings) {
+	envs.bind(v, prefix+".ca_file", "OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE", "OTEL_EXPORTER_OTLP_CERTIFICATE")
+	envs.bind(v, prefix+".cert_file", "OTEL_EXPORTER_OTLP_TRACES_CLIENT_CERTIFICATE", "OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE")
+	envs.bind(v, prefix+".key_file", "OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY", "OTEL_EXPORTER_OTLP_CLIENT_KEY")
+	envs.bind(v, prefix+".server_name_override", "OTEL_EXPORTER_OTLP_SERVER_NAME_OVERRIDE")
+}
 
-	_ = v.BindEnv(prefix+".disabled", "OTEL_SDK_DISABLED")
-	_ = v.BindEnv(prefix+".endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT")
-	_ = v.BindEnv(prefix+".insecure_skip_verify", "OTEL_EXPORTER_OTLP_INSECURE_SKIP_VERIFY")
-	_ = v.BindEnv(prefix+".service_name", "OTEL_SERVICE_NAME")
-	_ = v.BindEnv(prefix+".elastic_apm_secret_token", "ELASTIC_APM_SECRET_TOKEN")
+// Bind span limits configuration section.
+func (c *SpanLimits) Bind(prefix string, v *viper.Viper) {
+	c.bind(prefix, v, nil)
+}
+
+func (c *SpanLimits) bind(prefix string, v *viper.Viper, envs envBindings) {
+	envs.bind(v, prefix+".attribute_value_length_limit", "OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", "OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT")
+	envs.bind(v, prefix+".attribute_count_limit", "OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT", "OTEL_ATTRIBUTE_COUNT_LIMIT")
+	envs.bind(v, prefix+".event_count_limit", "OTEL_SPAN_EVENT_COUNT_LIMIT")
+	envs.bind(v, prefix+".link_count_limit", "OTEL_SPAN_LINK_COUNT_LIMIT")
+	envs.bind(v, prefix+".attribute_per_event_count_limit", "OTEL_EVENT_ATTRIBUTE_COUNT_LIMIT")
+	envs.bind(v, prefix+".attribute_per_link_count_limit", "OTEL_LINK_ATTRIBUTE_COUNT_LIMIT")
+}
+
+// Bind deployment environment configuration section.
+func (c *DeploymentEnvironment) Bind(prefix string, v *viper.Viper) {
+	c.bind(prefix, v, nil)
+}
+
+func (c *DeploymentEnvironment) bind(prefix string, v *viper.Viper, envs envBindings) {
+	// Elastic APM agent environment is used instead of application environment if set.
+	if os.Getenv("ELASTIC_APM_ENVIRONMENT") != "" {
+		v.SetDefault(prefix+".source", DeploymentEnvironmentSourceConfig)
+	} else {
+		v.SetDefault(prefix+".source", DeploymentEnvironmentSourceApp)
+	}
+
+	v.SetDefault(prefix+".casing", DeploymentEnvironmentCasingLower)
+
+	envs.bind(v, prefix+".name", "ELASTIC_APM_ENVIRONMENT")
+}
+
+// Resolve returns deployment environment name based on the configured source
+// and casing. Application environment is used if no other value is available.
+func (c *DeploymentEnvironment) Resolve(appEnv string) string {
+	var env string
+
+	switch c.Source {
+	case DeploymentEnvironmentSourceEnv:
+		if c.EnvVar != "" {
+			env = os.Getenv(c.EnvVar)
+		}
+	case DeploymentEnvironmentSourceConfig:
+		env = c.Name
+	}
+
+	if env == "" {
+		env = appEnv
+	}
+
+	switch c.Casing {
+	case DeploymentEnvironmentCasingUpper:
+		return strings.ToUpper(env)
+	case DeploymentEnvironmentCasingNone:
+		return env
+	default:
+		return strings.ToLower(env)
+	}
 }
 
 // IsDisabled returns true if the tracing is disabled.
 func (c *Configuration) IsDisabled() bool {
-	return c.Disabled || (c.Endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "")
+	if c.Disabled || c.Exporter == ExporterNone {
+		return true
+	}
+
+	// Console exporter does not require an endpoint.
+	if c.Exporter == ExporterConsole {
+		return false
+	}
+
+	return c.Endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == ""
 }
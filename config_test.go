@@ -0,0 +1,75 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/spf13/viper"
+)
+
+func TestDeploymentEnvironmentResolve(t *testing.T) {
+	t.Setenv("TEST_DEPLOYMENT_ENV", "Staging")
+
+	tests := []struct {
+		name     string
+		config   DeploymentEnvironment
+		expected string
+	}{
+		{name: "default", config: DeploymentEnvironment{}, expected: "production"},
+		{name: "app", config: DeploymentEnvironment{Source: DeploymentEnvironmentSourceApp, Casing: DeploymentEnvironmentCasingNone}, expected: "Production"},
+		{name: "env", config: DeploymentEnvironment{Source: DeploymentEnvironmentSourceEnv, EnvVar: "TEST_DEPLOYMENT_ENV"}, expected: "staging"},
+		{name: "env missing", config: DeploymentEnvironment{Source: DeploymentEnvironmentSourceEnv, EnvVar: "TEST_DEPLOYMENT_ENV_MISSING"}, expected: "production"},
+		{name: "config", config: DeploymentEnvironment{Source: DeploymentEnvironmentSourceConfig, Name: "prod-eu", Casing: DeploymentEnvironmentCasingUpper}, expected: "PROD-EU"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qt.Check(t, qt.Equals(test.config.Resolve("Production"), test.expected))
+		})
+	}
+}
+
+func TestBindElasticAPMEnv(t *testing.T) {
+	t.Setenv("ELASTIC_APM_SERVER_URL", "https://apm.example.com")
+	t.Setenv("ELASTIC_APM_SERVICE_NAME", "orders")
+	t.Setenv("ELASTIC_APM_ENVIRONMENT", "Staging")
+	t.Setenv("OTEL_SERVICE_NAME", "orders-api")
+
+	v := viper.New()
+
+	var cfg struct {
+		OpenTelemetry Configuration `mapstructure:"otel"`
+	}
+
+	c := &cfg.OpenTelemetry
+	c.Bind("otel", v)
+
+	qt.Assert(t, qt.IsNil(v.Unmarshal(&cfg)))
+	qt.Check(t, qt.Equals(c.Endpoint, "https://apm.example.com"))
+	qt.Check(t, qt.Equals(c.ServiceName, "orders-api"))
+	qt.Check(t, qt.Equals(c.DeploymentEnvironment.Resolve("Production"), "staging"))
+}
+
+func TestBindTLSEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_CERTIFICATE", "/etc/otel/ca.pem")
+	t.Setenv("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE", "/etc/otel/client.pem")
+	t.Setenv("OTEL_EXPORTER_OTLP_CLIENT_KEY", "/etc/otel/client.key")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY", "/etc/otel/traces.key")
+
+	v := viper.New()
+
+	var cfg struct {
+		OpenTelemetry Configuration `mapstructure:"otel"`
+	}
+
+	c := &cfg.OpenTelemetry
+	c.Bind("otel", v)
+
+	qt.Assert(t, qt.IsNil(v.Unmarshal(&cfg)))
+	qt.Check(t, qt.Equals(c.TLS.CAFile, "/etc/otel/ca.pem"))
+	qt.Check(t, qt.Equals(c.TLS.CertFile, "/etc/otel/client.pem"))
+	qt.Check(t, qt.Equals(c.TLS.KeyFile, "/etc/otel/traces.key"))
+}
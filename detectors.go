@@ -0,0 +1,346 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
+const (
+	// ResourceDetectorEC2 detects AWS EC2 instance attributes using instance metadata service.
+	ResourceDetectorEC2 = "ec2"
+	// ResourceDetectorECS detects AWS ECS task attributes using task metadata endpoint.
+	ResourceDetectorECS = "ecs"
+	// ResourceDetectorEKS detects AWS EKS worker node attributes using instance metadata service.
+	ResourceDetectorEKS = "eks"
+	// ResourceDetectorGCP detects Google Cloud attributes using metadata server.
+	ResourceDetectorGCP = "gcp"
+	// ResourceDetectorAzure detects Azure VM and AKS node attributes using instance metadata service.
+	ResourceDetectorAzure = "azure"
+)
+
+// resourceDetectorTimeout limits time spent on querying single metadata service.
+const resourceDetectorTimeout = 2 * time.Second
+
+var errNotDetected = errors.New("environment not detected")
+
+// newResourceDetectors returns resource detectors for the configured names.
+func newResourceDetectors(names []string) []resource.Detector {
+	detectors := make([]resource.Detector, 0, len(names))
+
+	client := &http.Client{Timeout: resourceDetectorTimeout}
+
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case ResourceDetectorEC2:
+			detectors = append(detectors, &ec2Detector{client: client, platform: semconv.CloudPlatformAWSEC2})
+		case ResourceDetectorEKS:
+			detectors = append(detectors, &ec2Detector{client: client, platform: semconv.CloudPlatformAWSEKS})
+		case ResourceDetectorECS:
+			detectors = append(detectors, &ecsDetector{client: client})
+		case ResourceDetectorGCP:
+			detectors = append(detectors, &gcpDetector{client: client})
+		case ResourceDetectorAzure:
+			detectors = append(detectors, &azureDetector{client: client})
+		}
+	}
+
+	return detectors
+}
+
+// detectResource runs configured resource detectors.
+//
+// Partial resource is returned along with the error if some of the detectors fail.
+func detectResource(ctx context.Context, names []string) (*resource.Resource, error) {
+	detectors := newResourceDetectors(names)
+	if len(detectors) == 0 {
+		return resource.Empty(), nil
+	}
+
+	return resource.New(ctx, resource.WithDetectors(detectors...))
+}
+
+func metadataGet(ctx context.Context, client *http.Client, method, url string, header map[string]string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return err
+	}
+
+	for k, val := range header {
+		req.Header.Set(k, val)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected metadata response status %d from %s", resp.StatusCode, url)
+	}
+
+	if s, ok := v.(*string); ok {
+		b, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if err != nil {
+			return err
+		}
+
+		*s = strings.TrimSpace(string(b))
+
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func newDetectedResource(attrs []attribute.KeyValue) *resource.Resource {
+	valid := make([]attribute.KeyValue, 0, len(attrs))
+
+	for _, kv := range attrs {
+		if kv.Value.Type() == attribute.STRING && kv.Value.AsString() == "" {
+			continue
+		}
+
+		valid = append(valid, kv)
+	}
+
+	return resource.NewWithAttributes(semconv.SchemaURL, valid...)
+}
+
+func isKubernetes() bool {
+	return os.Getenv("KUBERNETES_SERVICE_HOST") != ""
+}
+
+// ec2Detector detects AWS EC2 instance attributes using IMDSv2.
+type ec2Detector struct {
+	client   *http.Client
+	platform attribute.KeyValue
+}
+
+const ec2MetadataEndpoint = "http://169.254.169.254"
+
+func (d *ec2Detector) Detect(ctx context.Context) (*resource.Resource, error) {
+	if d.platform == semconv.CloudPlatformAWSEKS && !isKubernetes() {
+		return nil, fmt.Errorf("eks: %w", errNotDetected)
+	}
+
+	var token string
+	if err := metadataGet(ctx, d.client, http.MethodPut, ec2MetadataEndpoint+"/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "60",
+	}, &token); err != nil {
+		return nil, fmt.Errorf("ec2: requesting metadata token: %w", err)
+	}
+
+	var doc struct {
+		AccountID        string `json:"accountId"`
+		AvailabilityZone string `json:"availabilityZone"`
+		Region           string `json:"region"`
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+		ImageID          string `json:"imageId"`
+	}
+
+	if err := metadataGet(ctx, d.client, http.MethodGet, ec2MetadataEndpoint+"/latest/dynamic/instance-identity/document", map[string]string{
+		"X-aws-ec2-metadata-token": token,
+	}, &doc); err != nil {
+		return nil, fmt.Errorf("ec2: requesting instance identity: %w", err)
+	}
+
+	return newDetectedResource([]attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		d.platform,
+		semconv.CloudRegion(doc.Region),
+		semconv.CloudAvailabilityZone(doc.AvailabilityZone),
+		semconv.CloudAccountID(doc.AccountID),
+		semconv.HostID(doc.InstanceID),
+		semconv.HostType(doc.InstanceType),
+		semconv.HostImageID(doc.ImageID),
+	}), nil
+}
+
+// ecsDetector detects AWS ECS task attributes using task metadata endpoint v4.
+type ecsDetector struct {
+	client *http.Client
+}
+
+func (d *ecsDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	endpoint := os.Getenv("ECS_CONTAINER_METADATA_URI_V4")
+	if endpoint == "" {
+		return nil, fmt.Errorf("ecs: %w", errNotDetected)
+	}
+
+	var container struct {
+		DockerID     string `json:"DockerId"`
+		Name         string `json:"Name"`
+		ContainerARN string `json:"ContainerARN"`
+	}
+
+	if err := metadataGet(ctx, d.client, http.MethodGet, endpoint, nil, &container); err != nil {
+		return nil, fmt.Errorf("ecs: requesting container metadata: %w", err)
+	}
+
+	var task struct {
+		Cluster          string `json:"Cluster"`
+		TaskARN          string `json:"TaskARN"`
+		Family           string `json:"Family"`
+		Revision         string `json:"Revision"`
+		AvailabilityZone string `json:"AvailabilityZone"`
+		LaunchType       string `json:"LaunchType"`
+	}
+
+	if err := metadataGet(ctx, d.client, http.MethodGet, endpoint+"/task", nil, &task); err != nil {
+		return nil, fmt.Errorf("ecs: requesting task metadata: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSECS,
+		semconv.CloudAvailabilityZone(task.AvailabilityZone),
+		semconv.AWSECSTaskARN(task.TaskARN),
+		semconv.AWSECSTaskFamily(task.Family),
+		semconv.AWSECSTaskRevision(task.Revision),
+		semconv.AWSECSContainerARN(container.ContainerARN),
+		semconv.ContainerID(container.DockerID),
+		semconv.ContainerName(container.Name),
+	}
+
+	// Task ARN format: arn:aws:ecs:<region>:<account>:task/<cluster>/<id>
+	if parts := strings.SplitN(task.TaskARN, ":", 6); len(parts) == 6 {
+		attrs = append(attrs,
+			semconv.CloudRegion(parts[3]),
+			semconv.CloudAccountID(parts[4]),
+		)
+
+		cluster := task.Cluster
+		if cluster != "" && !strings.HasPrefix(cluster, "arn:") {
+			cluster = strings.Join(append(parts[:5:5], "cluster/"+cluster), ":")
+		}
+
+		attrs = append(attrs, semconv.AWSECSClusterARN(cluster))
+	}
+
+	switch strings.ToUpper(task.LaunchType) {
+	case "EC2":
+		attrs = append(attrs, semconv.AWSECSLaunchtypeEC2)
+	case "FARGATE":
+		attrs = append(attrs, semconv.AWSECSLaunchtypeFargate)
+	}
+
+	return newDetectedResource(attrs), nil
+}
+
+// gcpDetector detects Google Cloud attributes using metadata server.
+type gcpDetector struct {
+	client *http.Client
+}
+
+const gcpMetadataEndpoint = "http://metadata.google.internal/computeMetadata/v1/"
+
+func (d *gcpDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	header := map[string]string{"Metadata-Flavor": "Google"}
+
+	var projectID, zone string
+
+	if err := metadataGet(ctx, d.client, http.MethodGet, gcpMetadataEndpoint+"project/project-id", header, &projectID); err != nil {
+		return nil, fmt.Errorf("gcp: requesting project ID: %w", err)
+	}
+
+	if err := metadataGet(ctx, d.client, http.MethodGet, gcpMetadataEndpoint+"instance/zone", header, &zone); err != nil {
+		return nil, fmt.Errorf("gcp: requesting zone: %w", err)
+	}
+
+	// Zone format: projects/<project-number>/zones/<zone>
+	zone = zone[strings.LastIndexByte(zone, '/')+1:]
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderGCP,
+		semconv.CloudAccountID(projectID),
+		semconv.CloudAvailabilityZone(zone),
+	}
+
+	if i := strings.LastIndexByte(zone, '-'); i > 0 {
+		attrs = append(attrs, semconv.CloudRegion(zone[:i]))
+	}
+
+	switch {
+	case os.Getenv("K_SERVICE") != "":
+		attrs = append(attrs, semconv.CloudPlatformGCPCloudRun)
+
+		return newDetectedResource(attrs), nil
+	case isKubernetes():
+		attrs = append(attrs, semconv.CloudPlatformGCPKubernetesEngine)
+	default:
+		attrs = append(attrs, semconv.CloudPlatformGCPComputeEngine)
+	}
+
+	var instanceID, machineType string
+
+	if err := metadataGet(ctx, d.client, http.MethodGet, gcpMetadataEndpoint+"instance/id", header, &instanceID); err != nil {
+		return nil, fmt.Errorf("gcp: requesting instance ID: %w", err)
+	}
+
+	if err := metadataGet(ctx, d.client, http.MethodGet, gcpMetadataEndpoint+"instance/machine-type", header, &machineType); err != nil {
+		return nil, fmt.Errorf("gcp: requesting machine type: %w", err)
+	}
+
+	attrs = append(attrs,
+		semconv.HostID(instanceID),
+		semconv.HostType(machineType[strings.LastIndexByte(machineType, '/')+1:]),
+	)
+
+	return newDetectedResource(attrs), nil
+}
+
+// azureDetector detects Azure VM attributes using instance metadata service.
+type azureDetector struct {
+	client *http.Client
+}
+
+const azureMetadataEndpoint = "http://169.254.169.254/metadata/instance/compute?api-version=2021-12-13&format=json"
+
+func (d *azureDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	var compute struct {
+		Location       string `json:"location"`
+		Zone           string `json:"zone"`
+		VMID           string `json:"vmId"`
+		VMSize         string `json:"vmSize"`
+		SubscriptionID string `json:"subscriptionId"`
+		ResourceID     string `json:"resourceId"`
+	}
+
+	if err := metadataGet(ctx, d.client, http.MethodGet, azureMetadataEndpoint, map[string]string{
+		"Metadata": "true",
+	}, &compute); err != nil {
+		return nil, fmt.Errorf("azure: requesting instance metadata: %w", err)
+	}
+
+	platform := semconv.CloudPlatformAzureVM
+	if isKubernetes() {
+		platform = semconv.CloudPlatformAzureAKS
+	}
+
+	return newDetectedResource([]attribute.KeyValue{
+		semconv.CloudProviderAzure,
+		platform,
+		semconv.CloudRegion(compute.Location),
+		semconv.CloudAvailabilityZone(compute.Zone),
+		semconv.CloudAccountID(compute.SubscriptionID),
+		semconv.CloudResourceID(compute.ResourceID),
+		semconv.HostID(compute.VMID),
+		semconv.HostType(compute.VMSize),
+	}), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
+func TestECSDetector(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v4":
+			_, _ = w.Write([]byte(`{"DockerId":"abc123","Name":"app","ContainerARN":"arn:aws:ecs:eu-west-1:123456789012:container/test/1"}`))
+		case "/v4/task":
+			_, _ = w.Write([]byte(`{"Cluster":"test","TaskARN":"arn:aws:ecs:eu-west-1:123456789012:task/test/1","Family":"app","Revision":"3","AvailabilityZone":"eu-west-1a","LaunchType":"FARGATE"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("ECS_CONTAINER_METADATA_URI_V4", srv.URL+"/v4")
+
+	res, err := detectResource(context.Background(), []string{ResourceDetectorECS})
+	qt.Assert(t, qt.IsNil(err))
+
+	set := res.Set()
+
+	for _, kv := range []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSECS,
+		semconv.CloudRegion("eu-west-1"),
+		semconv.CloudAccountID("123456789012"),
+		semconv.AWSECSClusterARN("arn:aws:ecs:eu-west-1:123456789012:cluster/test"),
+		semconv.AWSECSLaunchtypeFargate,
+		semconv.ContainerID("abc123"),
+	} {
+		v, ok := set.Value(kv.Key)
+		qt.Check(t, qt.IsTrue(ok), qt.Commentf("attribute %s", kv.Key))
+		qt.Check(t, qt.Equals(v, kv.Value), qt.Commentf("attribute %s", kv.Key))
+	}
+}
+
+func TestECSDetectorNotDetected(t *testing.T) {
+	t.Setenv("ECS_CONTAINER_METADATA_URI_V4", "")
+
+	_, err := detectResource(context.Background(), []string{ResourceDetectorECS})
+	qt.Check(t, qt.ErrorIs(err, errNotDetected))
+}
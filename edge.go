@@ -0,0 +1,109 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"azugo.io/azugo"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	edgeTimingHeaderKey = attribute.Key("http.server.edge.timing_header")
+	edgeLatencyKey      = attribute.Key("http.server.edge.latency")
+	// requestDelayKey is the time in seconds between the request was read by the
+	// server and the server span was started.
+	requestDelayKey = attribute.Key("http.server.request.delay")
+)
+
+// DefaultEdgeTimingHeaders are the request headers checked for the time request
+// was received by the front proxy or CDN when no headers are specified.
+var DefaultEdgeTimingHeaders = []string{
+	"X-Request-Start",
+	"X-Queue-Start",
+	"CDN-Request-Start",
+}
+
+// EdgeTimingHeaders configures the Handler to read the time request was received
+// by the TLS-terminating front proxy or CDN from the first present request header
+// in the list and to record the edge-to-origin latency (in seconds) as
+// "http.server.edge.latency" server span attribute.
+//
+// Header value can be a Unix timestamp in seconds (with optional fraction),
+// milliseconds, microseconds or nanoseconds, optionally prefixed with "t="
+// as set by nginx and Heroku router. If no headers are specified,
+// DefaultEdgeTimingHeaders are used.
+func EdgeTimingHeaders(headers ...string) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		if len(headers) == 0 {
+			headers = DefaultEdgeTimingHeaders
+		}
+
+		cfg.edgeTimingHeaders = headers
+	})
+}
+
+// edgeLatencyAttributes returns edge-to-origin latency attributes if any of the
+// edge timing headers is present in the request.
+func edgeLatencyAttributes(ctx *azugo.Context, headers []string, now time.Time) []attribute.KeyValue {
+	for _, h := range headers {
+		v := ctx.Request().Header.Peek(h)
+		if len(v) == 0 {
+			continue
+		}
+
+		start, ok := parseEdgeTimestamp(string(v))
+		if !ok {
+			continue
+		}
+
+		latency := now.Sub(start)
+		if latency < 0 {
+			// Clocks are not in sync, do not report misleading values.
+			latency = 0
+		}
+
+		return []attribute.KeyValue{
+			edgeTimingHeaderKey.String(h),
+			edgeLatencyKey.Float64(latency.Seconds()),
+		}
+	}
+
+	return nil
+}
+
+// parseEdgeTimestamp parses Unix timestamp detecting its precision by magnitude.
+func parseEdgeTimestamp(v string) (time.Time, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "t=")
+
+	if strings.ContainsRune(v, '.') {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 {
+			return time.Time{}, false
+		}
+
+		sec := int64(f)
+
+		return time.Unix(sec, int64((f-float64(sec))*float64(time.Second))), true
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+
+	switch {
+	case n < 1e11:
+		return time.Unix(n, 0), true
+	case n < 1e14:
+		return time.UnixMilli(n), true
+	case n < 1e17:
+		return time.UnixMicro(n), true
+	default:
+		return time.Unix(0, n), true
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestParseEdgeTimestamp(t *testing.T) {
+	expected := time.Unix(1700000000, 123000000)
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "seconds with fraction", value: "1700000000.123"},
+		{name: "nginx", value: "t=1700000000.123"},
+		{name: "milliseconds", value: "1700000000123"},
+		{name: "microseconds", value: "t=1700000000123000"},
+		{name: "nanoseconds", value: "1700000000123000000"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts, ok := parseEdgeTimestamp(test.value)
+			qt.Assert(t, qt.IsTrue(ok))
+			qt.Check(t, qt.IsTrue(ts.Sub(expected).Abs() < time.Millisecond))
+		})
+	}
+
+	_, ok := parseEdgeTimestamp("invalid")
+	qt.Check(t, qt.IsFalse(ok))
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"net/url"
+	"sort"
+
+	"azugo.io/azugo"
+	"azugo.io/core"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const (
+	maskedValue = "***"
+	// exporterCustom is reported if the TraceExporter option is provided.
+	exporterCustom = "custom"
+)
+
+// EffectiveEndpoint is the resolved exporter endpoint with secrets masked.
+type EffectiveEndpoint struct {
+	// Name of the endpoint: "primary", "migration", "route" or "additional".
+	Name string `json:"name"`
+	// Endpoint URL with user password masked.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Headers sent to the endpoint with values masked.
+	Headers map[string]string `json:"headers,omitempty"`
+	// InsecureSkipVerify is set if TLS certificate verification is disabled.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+}
+
+// EffectiveConfiguration is the resolved telemetry configuration of the
+// running application to be used for fleet auditing and support.
+type EffectiveConfiguration struct {
+	// Enabled is false if OpenTelemetry is disabled.
+	Enabled bool `json:"enabled"`
+	// Exporter used to export traces.
+	Exporter string `json:"exporter,omitempty"`
+	// Endpoints traces are exported to.
+	Endpoints []EffectiveEndpoint `json:"endpoints,omitempty"`
+	// Sampler description. Empty if custom tracer provider or SDK default
+	// sampler configured with OTEL_TRACES_SAMPLER environment variable is used.
+	Sampler string `json:"sampler,omitempty"`
+	// Propagators fields used to propagate trace context.
+	Propagators []string `json:"propagators,omitempty"`
+	// IgnorePaths of requests that are not traced.
+	IgnorePaths []string `json:"ignore_paths,omitempty"`
+	// Filters is the number of request filters.
+	Filters int `json:"filters"`
+	// Recorders are names of the registered instrumentation recorders.
+	Recorders []string `json:"recorders,omitempty"`
+	// Resource attributes. Empty if custom tracer provider is used.
+	Resource map[string]string `json:"resource,omitempty"`
+	// Build information of the instrumentation.
+	Build BuildInfo `json:"build"`
+}
+
+// Effective returns the resolved telemetry configuration with secrets masked.
+func Effective(t core.Tasker) *EffectiveConfiguration {
+	s, ok := t.(*setup)
+	if !ok {
+		return &EffectiveConfiguration{
+			Build: Info(),
+		}
+	}
+
+	config := s.config
+
+	exporter := config.Exporter
+	if exporter == "" {
+		exporter = ExporterOTLP
+	}
+
+	if s.instr.cfg.traceExporter != nil {
+		exporter = exporterCustom
+	}
+
+	c := &EffectiveConfiguration{
+		Enabled:     true,
+		Exporter:    exporter,
+		Propagators: s.instr.cfg.Propagators.Fields(),
+		IgnorePaths: config.IgnorePaths,
+		Filters:     s.filters,
+		Recorders:   s.instr.names(),
+		Build:       Info(),
+	}
+
+	if s.res != nil {
+		if sampler := newSampler(config, s.routes); sampler != nil {
+			c.Sampler = sampler.Description()
+		}
+
+		c.Resource = resourceAttributes(s.res)
+	}
+
+	c.Build.Features = s.features()
+
+	if exporter != ExporterOTLP {
+		return c
+	}
+
+	headers := make(map[string]string, len(config.Headers)+1)
+	for k := range config.Headers {
+		headers[k] = maskedValue
+	}
+
+	if config.ElasticAPMSecretToken != "" || config.ElasticAPMAPIKey != "" {
+		headers["Authorization"] = maskedValue
+	}
+
+	c.Endpoints = append(c.Endpoints, EffectiveEndpoint{
+		Name:               "primary",
+		Endpoint:           redactEndpoint(config.Endpoint),
+		Headers:            headers,
+		InsecureSkipVerify: config.InsecureSkipVerify || config.TLS.InsecureSkipVerify,
+	})
+
+	if m := config.Migration; m.Endpoint != "" {
+		e := EffectiveEndpoint{
+			Name:               "migration",
+			Endpoint:           redactEndpoint(m.Endpoint),
+			InsecureSkipVerify: m.InsecureSkipVerify,
+		}
+
+		if m.ElasticAPMSecretToken != "" || m.ElasticAPMAPIKey != "" {
+			e.Headers = map[string]string{"Authorization": maskedValue}
+		}
+
+		c.Endpoints = append(c.Endpoints, e)
+	}
+
+	for _, r := range config.RouteExporters {
+		c.Endpoints = append(c.Endpoints, effectiveEndpoint("route", &r.ExporterEndpoint))
+	}
+
+	for _, a := range config.AdditionalEndpoints {
+		c.Endpoints = append(c.Endpoints, effectiveEndpoint("additional", &a))
+	}
+
+	return c
+}
+
+func effectiveEndpoint(name string, a *ExporterEndpoint) EffectiveEndpoint {
+	e := EffectiveEndpoint{
+		Name:               name,
+		Endpoint:           redactEndpoint(a.Endpoint),
+		Headers:            make(map[string]string, len(a.Headers)+1),
+		InsecureSkipVerify: a.InsecureSkipVerify,
+	}
+
+	for k := range a.Headers {
+		e.Headers[k] = maskedValue
+	}
+
+	if a.ElasticAPMSecretToken != "" || a.ElasticAPMAPIKey != "" {
+		e.Headers["Authorization"] = maskedValue
+	}
+
+	return e
+}
+
+// EffectiveHandler returns request handler that responds with the resolved
+// telemetry configuration as JSON. The handler is not protected, so it must
+// be registered behind authorization.
+func EffectiveHandler(t core.Tasker) azugo.RequestHandler {
+	return func(ctx *azugo.Context) {
+		ctx.JSON(Effective(t))
+	}
+}
+
+func redactEndpoint(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return maskedValue
+	}
+
+	return u.Redacted()
+}
+
+func resourceAttributes(res *resource.Resource) map[string]string {
+	attrs := make(map[string]string, res.Len())
+
+	for _, kv := range res.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+
+	return attrs
+}
+
+// names returns sorted names of the registered recorders.
+func (i *instrumentation) names() []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	names := make([]string, 0, len(i.recorders))
+
+	for _, recorders := range i.recorders {
+		for _, r := range recorders {
+			if _, ok := seen[r.Name]; ok {
+				continue
+			}
+
+			seen[r.Name] = struct{}{}
+			names = append(names, r.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// features returns sorted names of the features enabled by the resolved configuration.
+func (s *setup) features() []string {
+	cfg := s.instr.cfg
+
+	// Metrics are not reported as it is not known if the global meter provider
+	// delegates to an SDK one.
+	features := []string{"traces", "http-server"}
+
+	features = append(features, s.instr.names()...)
+
+	if cfg.userClaim != nil && len(s.config.ClaimAttributes) > 0 {
+		features = append(features, "user-claims")
+	}
+
+	// Sampling and span processing is configured only for the created tracer provider.
+	if s.res != nil {
+		if s.config.TailSampling.Enabled {
+			features = append(features, "tail-sampling")
+		}
+
+		if len(s.config.RouteSampling) > 0 {
+			features = append(features, "route-sampling")
+		}
+
+		if len(s.config.TenantSampling.Rates) > 0 || s.config.TenantSampling.DefaultRate > 0 {
+			features = append(features, "tenant-sampling")
+		}
+
+		if s.config.NPlusOne.Enabled {
+			features = append(features, "n-plus-one")
+		}
+
+		if len(s.config.ScrubAttributes) > 0 {
+			features = append(features, "attribute-scrubbing")
+		}
+	}
+
+	sort.Strings(features)
+
+	return features
+}
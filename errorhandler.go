@@ -0,0 +1,47 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+const errorLogInterval = 10 * time.Second
+
+// errorHandler logs OpenTelemetry SDK and export errors to the application
+// logger instead of the standard error output. Errors are rate-limited and
+// the number of suppressed errors is logged with the next one.
+type errorHandler struct {
+	logger     *zap.Logger
+	lastLog    atomic.Int64
+	suppressed atomic.Int64
+}
+
+var _ otel.ErrorHandler = (*errorHandler)(nil)
+
+func (h *errorHandler) Handle(err error) {
+	if err == nil {
+		return
+	}
+
+	now := time.Now().UnixNano()
+
+	last := h.lastLog.Load()
+	if now-last < int64(errorLogInterval) || !h.lastLog.CompareAndSwap(last, now) {
+		h.suppressed.Add(1)
+
+		return
+	}
+
+	fields := []zap.Field{zap.Error(err)}
+	if n := h.suppressed.Swap(0); n > 0 {
+		fields = append(fields, zap.Int64("suppressed", n))
+	}
+
+	h.logger.Warn("Open Telemetry error", fields...)
+}
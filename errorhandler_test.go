@@ -0,0 +1,34 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestErrorHandlerRateLimit(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+
+	h := &errorHandler{logger: zap.New(core)}
+
+	h.Handle(errors.New("export failed"))
+	h.Handle(errors.New("export failed"))
+	h.Handle(nil)
+
+	qt.Assert(t, qt.Equals(logs.Len(), 1))
+	qt.Check(t, qt.Equals(h.suppressed.Load(), int64(1)))
+
+	// Allow next error to be logged.
+	h.lastLog.Store(0)
+	h.Handle(errors.New("export failed"))
+
+	entries := logs.All()
+	qt.Assert(t, qt.HasLen(entries, 2))
+	qt.Check(t, qt.Equals(entries[1].ContextMap()["suppressed"], any(int64(1))))
+}
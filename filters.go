@@ -0,0 +1,134 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"path"
+	"strings"
+
+	"azugo.io/azugo"
+	"github.com/valyala/fasthttp"
+)
+
+// FilterPath returns a filter that excludes requests with path equal to or
+// under any of the provided prefixes from tracing. Prefixes are matched on the
+// path segment boundary, so "/api" matches "/api" and "/api/users" but not
+// "/apis".
+func FilterPath(prefixes ...string) Filter {
+	return func(ctx *azugo.Context) bool {
+		path := ctx.Path()
+
+		for _, prefix := range prefixes {
+			if hasPathPrefix(path, prefix) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// hasPathPrefix reports whether the path is equal to or under the prefix.
+func hasPathPrefix(p, prefix string) bool {
+	if !strings.HasPrefix(p, prefix) {
+		return false
+	}
+
+	return len(p) == len(prefix) || strings.HasSuffix(prefix, "/") || p[len(prefix)] == '/'
+}
+
+// FilterMethod returns a filter that excludes requests with any of the provided
+// HTTP methods from tracing.
+func FilterMethod(methods ...string) Filter {
+	return func(ctx *azugo.Context) bool {
+		method := ctx.Method()
+
+		for _, m := range methods {
+			if strings.EqualFold(method, m) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// FilterHealthChecks returns a filter that excludes common health check and
+// metrics endpoints ("/healthz", "/livez", "/readyz" and "/metrics") from tracing.
+func FilterHealthChecks() Filter {
+	return func(ctx *azugo.Context) bool {
+		switch ctx.Path() {
+		case "/healthz", "/livez", "/readyz", "/metrics":
+			return false
+		default:
+			return true
+		}
+	}
+}
+
+// FilterPreflight returns a filter that excludes CORS preflight requests
+// ("OPTIONS" requests with "Access-Control-Request-Method" header) from tracing.
+func FilterPreflight() Filter {
+	return func(ctx *azugo.Context) bool {
+		if ctx.Method() != fasthttp.MethodOptions {
+			return true
+		}
+
+		return len(ctx.Request().Header.Peek(fasthttp.HeaderAccessControlRequestMethod)) == 0
+	}
+}
+
+// skipList matches requests configured by "skip_paths" and "skip_user_agents"
+// configuration keys that are passed through without any tracing.
+type skipList struct {
+	paths      []string
+	userAgents []string
+}
+
+func newSkipList(config *Configuration) *skipList {
+	if len(config.SkipPaths) == 0 && len(config.SkipUserAgents) == 0 {
+		return nil
+	}
+
+	return &skipList{
+		paths:      config.SkipPaths,
+		userAgents: config.SkipUserAgents,
+	}
+}
+
+func (l *skipList) skip(ctx *azugo.Context) bool {
+	if l == nil {
+		return false
+	}
+
+	return l.matchPath(ctx.Path()) || l.matchUserAgent(string(ctx.Request().Header.UserAgent()))
+}
+
+func (l *skipList) matchPath(p string) bool {
+	for _, pattern := range l.paths {
+		if pattern == p {
+			return true
+		}
+
+		if ok, err := path.Match(pattern, p); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *skipList) matchUserAgent(ua string) bool {
+	if ua == "" {
+		return false
+	}
+
+	for _, prefix := range l.userAgents {
+		if strings.HasPrefix(ua, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,31 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestHasPathPrefix(t *testing.T) {
+	tests := []struct {
+		path     string
+		prefix   string
+		expected bool
+	}{
+		{path: "/api", prefix: "/api", expected: true},
+		{path: "/api/users", prefix: "/api", expected: true},
+		{path: "/apis", prefix: "/api", expected: false},
+		{path: "/static/app.js", prefix: "/static/", expected: true},
+		{path: "/static", prefix: "/static/", expected: false},
+		{path: "/health", prefix: "/healthz", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path+" "+test.prefix, func(t *testing.T) {
+			qt.Check(t, qt.Equals(hasPathPrefix(test.path, test.prefix), test.expected))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FatalFlushTimeout is the maximum time to wait for pending telemetry to be
+// exported before the process exits due to a fatal condition.
+const FatalFlushTimeout = 3 * time.Second
+
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// ForceFlush synchronously exports all pending telemetry of the global providers.
+func ForceFlush(ctx context.Context) error {
+	return globalProviders().ForceFlush(ctx)
+}
+
+// ForceFlush synchronously exports all pending telemetry of the providers.
+func (p *Providers) ForceFlush(ctx context.Context) error {
+	var err error
+
+	if f, ok := p.TracerProvider.(flusher); ok {
+		err = errors.Join(err, f.ForceFlush(ctx))
+	}
+
+	if f, ok := p.MeterProvider.(flusher); ok {
+		err = errors.Join(err, f.ForceFlush(ctx))
+	}
+
+	return err
+}
+
+func (p *Providers) fatalFlush() {
+	ctx, cancel := context.WithTimeout(context.Background(), FatalFlushTimeout)
+	defer cancel()
+
+	_ = p.ForceFlush(ctx)
+}
+
+// FlushOnPanic flushes pending telemetry of the global providers if the
+// goroutine is panicking and continues panicking afterwards. It must be
+// deferred directly:
+//
+//	defer opentelemetry.FlushOnPanic()
+func FlushOnPanic() {
+	if r := recover(); r != nil {
+		globalProviders().fatalFlush()
+
+		panic(r)
+	}
+}
+
+// FlushOnPanic flushes pending telemetry of the providers if the goroutine is
+// panicking and continues panicking afterwards. It must be deferred directly:
+//
+//	defer providers.FlushOnPanic()
+func (p *Providers) FlushOnPanic() {
+	if r := recover(); r != nil {
+		p.fatalFlush()
+
+		panic(r)
+	}
+}
+
+type fatalHook struct {
+	providers *Providers
+}
+
+func (h fatalHook) OnWrite(_ *zapcore.CheckedEntry, _ []zapcore.Field) {
+	providers := h.providers
+	if providers == nil {
+		providers = globalProviders()
+	}
+
+	providers.fatalFlush()
+
+	os.Exit(1)
+}
+
+// FatalHook returns zap hook that flushes pending telemetry of the global
+// providers before exiting the process on fatal log entries:
+//
+//	logger = logger.WithOptions(zap.WithFatalHook(opentelemetry.FatalHook()))
+func FatalHook() zapcore.CheckWriteHook {
+	return fatalHook{}
+}
+
+// FatalHook returns zap hook that flushes pending telemetry of the providers
+// before exiting the process on fatal log entries.
+func (p *Providers) FatalHook() zapcore.CheckWriteHook {
+	return fatalHook{providers: p}
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"sync/atomic"
+
+	"azugo.io/azugo"
+	"azugo.io/core"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
+// Health of the trace export pipeline.
+type Health struct {
+	// Enabled is false if OpenTelemetry is disabled or custom tracer provider is used.
+	Enabled bool `json:"enabled"`
+	// Enqueued is the number of spans passed to the batch span processors.
+	Enqueued int64 `json:"enqueued"`
+	// Exported is the number of successfully exported spans.
+	Exported int64 `json:"exported"`
+	// Failed is the number of spans that failed to export.
+	Failed int64 `json:"failed"`
+	// ExportErrors is the number of failed export requests.
+	ExportErrors int64 `json:"export_errors"`
+	// Pending is the number of enqueued spans not exported yet including
+	// spans dropped by batch span processors because the queue was full.
+	Pending int64 `json:"pending"`
+	// LastError is the last export error.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// exportStats counts spans passing through the trace export pipeline.
+type exportStats struct {
+	enqueued  atomic.Int64
+	exported  atomic.Int64
+	failed    atomic.Int64
+	errors    atomic.Int64
+	lastError atomic.Value
+}
+
+// batchSpanProcessor returns batch span processor for the exporter that
+// counts processed spans.
+func (s *exportStats) batchSpanProcessor(exporter trace.SpanExporter) trace.SpanProcessor {
+	return &statsSpanProcessor{
+		SpanProcessor: trace.NewBatchSpanProcessor(&statsSpanExporter{
+			SpanExporter: exporter,
+			stats:        s,
+		}),
+		stats: s,
+	}
+}
+
+func (s *exportStats) health() *Health {
+	h := &Health{
+		Enabled:      true,
+		Enqueued:     s.enqueued.Load(),
+		Exported:     s.exported.Load(),
+		Failed:       s.failed.Load(),
+		ExportErrors: s.errors.Load(),
+	}
+
+	h.Pending = max(h.Enqueued-h.Exported-h.Failed, 0)
+
+	if err, ok := s.lastError.Load().(string); ok {
+		h.LastError = err
+	}
+
+	return h
+}
+
+// register export pipeline metrics.
+func (s *exportStats) register(mp metric.MeterProvider) {
+	meter := mp.Meter(
+		ScopeName,
+		metric.WithInstrumentationVersion(Version()),
+		metric.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
+
+	counters := []struct {
+		name        string
+		description string
+		unit        string
+		value       *atomic.Int64
+	}{
+		{"azugo.telemetry.spans.enqueued", "Number of spans passed to the batch span processors.", "{span}", &s.enqueued},
+		{"azugo.telemetry.spans.exported", "Number of successfully exported spans.", "{span}", &s.exported},
+		{"azugo.telemetry.spans.failed", "Number of spans that failed to export.", "{span}", &s.failed},
+		{"azugo.telemetry.export.errors", "Number of failed export requests.", "{request}", &s.errors},
+	}
+
+	for _, c := range counters {
+		_, err := meter.Int64ObservableCounter(
+			c.name,
+			metric.WithDescription(c.description),
+			metric.WithUnit(c.unit),
+			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+				o.Observe(c.value.Load())
+
+				return nil
+			}),
+		)
+		if err != nil {
+			otel.Handle(err)
+		}
+	}
+
+	_, err := meter.Int64ObservableUpDownCounter(
+		"azugo.telemetry.spans.pending",
+		metric.WithDescription("Number of enqueued spans not exported yet."),
+		metric.WithUnit("{span}"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(s.health().Pending)
+
+			return nil
+		}),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+}
+
+type statsSpanProcessor struct {
+	trace.SpanProcessor
+
+	stats *exportStats
+}
+
+func (p *statsSpanProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.stats.enqueued.Add(1)
+	}
+
+	p.SpanProcessor.OnEnd(s)
+}
+
+type statsSpanExporter struct {
+	trace.SpanExporter
+
+	stats *exportStats
+}
+
+func (e *statsSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err != nil {
+		e.stats.failed.Add(int64(len(spans)))
+		e.stats.errors.Add(1)
+		e.stats.lastError.Store(err.Error())
+
+		return err
+	}
+
+	e.stats.exported.Add(int64(len(spans)))
+
+	return nil
+}
+
+// PipelineHealth returns health of the trace export pipeline.
+func PipelineHealth(t core.Tasker) *Health {
+	s, ok := t.(*setup)
+	if !ok || s.stats == nil {
+		return &Health{}
+	}
+
+	return s.stats.health()
+}
+
+// HealthHandler returns request handler that responds with the trace export
+// pipeline health as JSON, for example to be registered as "/otel/health".
+func HealthHandler(t core.Tasker) azugo.RequestHandler {
+	return func(ctx *azugo.Context) {
+		ctx.JSON(PipelineHealth(t))
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type failingExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (failingExporter) ExportSpans(context.Context, []trace.ReadOnlySpan) error {
+	return errors.New("connection refused")
+}
+
+func TestExportStats(t *testing.T) {
+	stats := &exportStats{}
+
+	tp := trace.NewTracerProvider(
+		trace.WithSpanProcessor(stats.batchSpanProcessor(tracetest.NewInMemoryExporter())),
+		trace.WithSpanProcessor(stats.batchSpanProcessor(failingExporter{tracetest.NewInMemoryExporter()})),
+	)
+
+	_, span := tp.Tracer("test").Start(context.Background(), "test")
+	span.End()
+
+	qt.Assert(t, qt.ErrorMatches(tp.ForceFlush(context.Background()), "connection refused"))
+
+	h := stats.health()
+	qt.Check(t, qt.IsTrue(h.Enabled))
+	qt.Check(t, qt.Equals(h.Enqueued, int64(2)))
+	qt.Check(t, qt.Equals(h.Exported, int64(1)))
+	qt.Check(t, qt.Equals(h.Failed, int64(1)))
+	qt.Check(t, qt.Equals(h.ExportErrors, int64(1)))
+	qt.Check(t, qt.Equals(h.Pending, int64(0)))
+	qt.Check(t, qt.Equals(h.LastError, "connection refused"))
+}
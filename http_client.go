@@ -6,16 +6,132 @@ package opentelemetry
 import (
 	"context"
 	"strings"
+	"sync"
+	"time"
 
 	"azugo.io/opentelemetry/internal/semconvutil"
 
 	"azugo.io/core/http"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-func httpClientRecorder(ctx context.Context, tracer oteltrace.Tracer, propagator propagation.TextMapPropagator, spfmt InstrumentationSpanNameFormatter, op string, args ...any) (func(err error), bool) {
+// maxClientAttempts limits number of outgoing requests tracked for resends.
+const maxClientAttempts = 1024
+
+type clientAttempt struct {
+	first   oteltrace.SpanContext
+	last    oteltrace.SpanContext
+	resends int
+}
+
+// clientAttempts keeps track of outgoing HTTP requests made while handling
+// single incoming request to detect when the same request is being resent.
+type clientAttempts struct {
+	mu       sync.Mutex
+	requests map[*http.Request]*clientAttempt
+}
+
+// track registers new attempt for the request. If request still carries trace context
+// injected for its previous attempt it is considered to be a retry and the first attempt
+// span context is returned together with the resend count.
+func (a *clientAttempts) track(req *http.Request, prev, sc oteltrace.SpanContext) (oteltrace.SpanContext, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.requests == nil {
+		a.requests = make(map[*http.Request]*clientAttempt)
+	}
+
+	attempt, ok := a.requests[req]
+	if !ok || !prev.IsValid() || !prev.Equal(attempt.last) {
+		// Requests are not removed when completed as it is not known if they
+		// will be resent, so tracking starts over when the limit is reached.
+		if !ok && len(a.requests) >= maxClientAttempts {
+			clear(a.requests)
+		}
+
+		a.requests[req] = &clientAttempt{
+			first: sc,
+			last:  sc,
+		}
+
+		return sc, 0
+	}
+
+	attempt.last = sc
+	attempt.resends++
+
+	return attempt.first, attempt.resends
+}
+
+// PropagationFields returns names of the headers that are set by the global
+// propagator when trace context is injected into the outgoing requests. Request
+// signers can use it to exclude these headers from the signature. Use
+// PropagationFields method of the providers with IsolatedProviders option.
+func PropagationFields() []string {
+	return globalProviders().PropagationFields()
+}
+
+// PropagationFields returns names of the headers that are set by the propagator
+// of the providers when trace context is injected into the outgoing requests.
+func (p *Providers) PropagationFields() []string {
+	return p.Propagator.Fields()
+}
+
+// signClientRequests returns instrumenter that only signs outgoing HTTP client
+// requests when telemetry is disabled.
+func signClientRequests(signer ClientRequestSigner) func(ctx context.Context, op string, args ...any) func(err error) {
+	return func(ctx context.Context, op string, args ...any) func(err error) {
+		if req, _, ok := http.InstrRequest(op, args...); ok {
+			signer(ctx, req)
+		}
+
+		return func(_ error) {}
+	}
+}
+
+type httpClientRecorder struct {
+	signer       ClientRequestSigner
+	cardinality  *cardinalityGuard
+	attrs        []attribute.KeyValue
+	peerServices map[string]string
+	duration     metric.Float64Histogram
+	payloads     *payloadCapture
+	// attempts tracks requests made outside of the traced incoming requests,
+	// for example from background jobs.
+	attempts clientAttempts
+}
+
+// newHTTPClientRecorder returns HTTP client recorder that calls signer after
+// the trace context has been injected into the request.
+func newHTTPClientRecorder(cfg *otelcfg) InstrumentationRecorderFunc {
+	r := &httpClientRecorder{
+		signer:       cfg.clientRequestSigner,
+		cardinality:  cfg.cardinality,
+		attrs:        cfg.clientAttributes,
+		peerServices: cfg.peerServices,
+		payloads:     newPayloadCapture(cfg),
+		duration: newDurationHistogram(
+			cfg.MeterProvider.Meter(
+				ScopeName+"/http-client",
+				metric.WithInstrumentationVersion(Version()),
+				metric.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+			),
+			"http.client.request.duration",
+			"Duration of HTTP client requests.",
+			cfg.durationBuckets,
+		),
+	}
+
+	return r.record
+}
+
+func (r *httpClientRecorder) record(ctx context.Context, tracer oteltrace.Tracer, propagator propagation.TextMapPropagator, spfmt InstrumentationSpanNameFormatter, op string, args ...any) (func(err error), bool) {
 	c := FromContext(ctx)
 
 	req, resp, ok := http.InstrRequest(op, args...)
@@ -25,11 +141,15 @@ func httpClientRecorder(ctx context.Context, tracer oteltrace.Tracer, propagator
 
 	opts := []oteltrace.SpanStartOption{
 		oteltrace.WithAttributes(
-			semconvutil.HTTPClientRequest(req)...,
+			r.cardinality.filter(ctx, semconvutil.HTTPClientRequest(req, r.peerServices))...,
 		),
 		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
 	}
 
+	if len(r.attrs) > 0 {
+		opts = append(opts, oteltrace.WithAttributes(r.attrs...))
+	}
+
 	spanName := spfmt(ctx, op, args...)
 	if spanName == "" {
 		var s strings.Builder
@@ -44,13 +164,39 @@ func httpClientRecorder(ctx context.Context, tracer oteltrace.Tracer, propagator
 		spanName = s.String()
 	}
 
+	// Trace context left from the previous attempt if the request is being resent.
+	prev := oteltrace.SpanContextFromContext(propagator.Extract(context.Background(), (*headerCarrier)(req)))
+
 	//nolint:spancheck
 	c, span := tracer.Start(c, spanName, opts...)
 
+	attempts := &r.attempts
+	if state := requestStateFromContext(ctx); state != nil {
+		attempts = &state.clientAttempts
+	}
+
+	if first, resends := attempts.track(req, prev, span.SpanContext()); resends > 0 {
+		span.SetAttributes(semconv.HTTPRequestResendCount(resends))
+		span.AddLink(oteltrace.Link{SpanContext: first})
+	}
+
 	propagator.Inject(c, (*headerCarrier)(req))
 
+	// Streamed bodies are not captured as reading them would consume the stream.
+	if r.payloads != nil && !req.IsBodyStream() && len(req.Header.ContentEncoding()) == 0 {
+		r.payloads.record(span, clientRequestBodyEventName, string(req.Header.ContentType()), req.Body())
+	}
+
+	if r.signer != nil {
+		r.signer(ctx, req)
+	}
+
+	start := time.Now()
+
 	//nolint:spancheck
 	return func(err error) {
+		mAttrs := r.cardinality.filter(ctx, semconvutil.HTTPClientRequestMetrics(req))
+
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 
@@ -58,13 +204,23 @@ func httpClientRecorder(ctx context.Context, tracer oteltrace.Tracer, propagator
 
 			span.End()
 
+			mAttrs = append(mAttrs, semconv.ErrorTypeOther)
+			r.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(mAttrs...))
+
 			return
 		}
 
 		span.SetAttributes(semconvutil.HTTPClientResponse(resp)...)
 
-		span.SetStatus(semconvutil.HTTPServerStatus(resp.StatusCode()))
+		if r.payloads != nil && !resp.IsBodyStream() && len(resp.Header.ContentEncoding()) == 0 {
+			r.payloads.record(span, clientResponseBodyEventName, string(resp.Header.ContentType()), resp.Body())
+		}
+
+		span.SetStatus(semconvutil.HTTPClientStatus(resp.StatusCode()))
 
 		span.End()
+
+		mAttrs = append(mAttrs, semconv.HTTPResponseStatusCode(resp.StatusCode()))
+		r.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(mAttrs...))
 	}, true
 }
@@ -0,0 +1,43 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"testing"
+
+	"azugo.io/core/http"
+	"github.com/go-quicktest/qt"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestClientAttemptsTrack(t *testing.T) {
+	var a clientAttempts
+
+	req := &http.Request{}
+
+	sc := func(id byte) oteltrace.SpanContext {
+		return oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID: oteltrace.TraceID{1},
+			SpanID:  oteltrace.SpanID{id},
+		})
+	}
+
+	first, resends := a.track(req, oteltrace.SpanContext{}, sc(1))
+	qt.Check(t, qt.Equals(resends, 0))
+	qt.Check(t, qt.IsTrue(first.Equal(sc(1))))
+
+	first, resends = a.track(req, sc(1), sc(2))
+	qt.Check(t, qt.Equals(resends, 1))
+	qt.Check(t, qt.IsTrue(first.Equal(sc(1))))
+
+	first, resends = a.track(req, sc(2), sc(3))
+	qt.Check(t, qt.Equals(resends, 2))
+	qt.Check(t, qt.IsTrue(first.Equal(sc(1))))
+
+	for range maxClientAttempts {
+		a.track(&http.Request{}, oteltrace.SpanContext{}, sc(4))
+	}
+
+	qt.Check(t, qt.IsTrue(len(a.requests) <= maxClientAttempts))
+}
@@ -5,8 +5,9 @@ package opentelemetry
 
 import (
 	"context"
+	"sync"
 
-	"azugo.io/core/instrumenter"
+	"azugo.io/core"
 	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
@@ -20,51 +21,110 @@ type namedRecorder struct {
 	Recorder InstrumentationRecorderFunc
 }
 
-func instr(opts ...Option) instrumenter.Instrumenter {
+// instrumentation records azugo instrumentation events using the registered
+// recorders. Recorders can be registered at any time, tracers are created lazily.
+type instrumentation struct {
+	cfg *otelcfg
+
+	mu        sync.RWMutex
+	tracers   map[string]oteltrace.Tracer
+	recorders map[string][]namedRecorder
+}
+
+func newInstrumentation(opts ...Option) *instrumentation {
 	cfg := traceConfig(opts...)
 
-	tracers := make(map[string]oteltrace.Tracer, len(cfg.instrRecorders))
-	recorders := make(map[string][]namedRecorder, len(cfg.instrRecorders))
+	i := &instrumentation{
+		cfg:       cfg,
+		tracers:   make(map[string]oteltrace.Tracer, len(cfg.instrRecorders)),
+		recorders: make(map[string][]namedRecorder, len(cfg.instrRecorders)),
+	}
 
 	for _, r := range cfg.instrRecorders {
-		if _, ok := tracers[r.Name]; !ok {
-			tracers[r.Name] = cfg.TracerProvider.Tracer(
-				ScopeName+"/"+r.Name,
-				oteltrace.WithInstrumentationVersion(Version()),
-				oteltrace.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
-			)
-		}
+		i.register(r.Name, r.Recorder, r.Ops...)
+	}
 
-		for _, op := range r.Ops {
-			recorders[op] = append(recorders[op], namedRecorder{
-				Name:     r.Name,
-				Recorder: r.Recorder,
-			})
-		}
+	return i
+}
 
-		if len(r.Ops) == 0 {
-			recorders[""] = append(recorders[""], namedRecorder{
-				Name:     r.Name,
-				Recorder: r.Recorder,
-			})
-		}
+func (i *instrumentation) register(name string, recorder InstrumentationRecorderFunc, ops ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	r := namedRecorder{
+		Name:     name,
+		Recorder: recorder,
 	}
 
-	return func(ctx context.Context, op string, args ...interface{}) func(err error) {
-		for _, r := range recorders[op] {
-			f, handled := r.Recorder(ctx, tracers[r.Name], cfg.Propagators, cfg.instrSpanNameFormatter, op, args...)
-			if handled {
-				return f
+	for _, op := range ops {
+		i.recorders[op] = append(i.recorders[op], r)
+	}
+
+	if len(ops) == 0 {
+		i.recorders[""] = append(i.recorders[""], r)
+	}
+}
+
+func (i *instrumentation) tracer(name string) oteltrace.Tracer {
+	i.mu.RLock()
+	t, ok := i.tracers[name]
+	i.mu.RUnlock()
+
+	if ok {
+		return t
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if t, ok = i.tracers[name]; ok {
+		return t
+	}
+
+	t = i.cfg.TracerProvider.Tracer(
+		ScopeName+"/"+name,
+		oteltrace.WithInstrumentationVersion(Version()),
+		oteltrace.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
+
+	i.tracers[name] = t
+
+	return t
+}
+
+func (i *instrumentation) record(ctx context.Context, op string, args ...interface{}) func(err error) {
+	// Recorder slices are only appended to, so they can be used without holding the lock.
+	i.mu.RLock()
+	opRecorders, anyRecorders := i.recorders[op], i.recorders[""]
+	i.mu.RUnlock()
+
+	for _, recorders := range [][]namedRecorder{opRecorders, anyRecorders} {
+		for _, r := range recorders {
+			spfmt, ok := i.cfg.recorderSpanNames[r.Name]
+			if !ok {
+				spfmt = i.cfg.instrSpanNameFormatter
 			}
-		}
 
-		for _, r := range recorders[""] {
-			f, handled := r.Recorder(ctx, tracers[r.Name], cfg.Propagators, cfg.instrSpanNameFormatter, op, args...)
+			f, handled := r.Recorder(ctx, i.tracer(r.Name), i.cfg.Propagators, spfmt, op, args...)
 			if handled {
 				return f
 			}
 		}
+	}
 
-		return func(_ error) {}
+	return func(_ error) {}
+}
+
+// RegisterInstrumentationRecorder registers recorder for specific instrumentation
+// operations after the application has been started, for example by runtime plugins.
+// It returns false if OpenTelemetry is disabled and the recorder has not been registered.
+func RegisterInstrumentationRecorder(t core.Tasker, name string, recorder InstrumentationRecorderFunc, ops ...string) bool {
+	s, ok := t.(*setup)
+	if !ok || s.instr == nil {
+		return false
 	}
+
+	s.instr.register(name, recorder, ops...)
+
+	return true
 }
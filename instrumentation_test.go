@@ -0,0 +1,42 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRecorderSpanNameFormatter(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	in := newInstrumentation(
+		TracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(recorder))),
+		InstrumentationSpanNameFormatter(func(_ context.Context, op string, _ ...any) string {
+			return "global " + op
+		}),
+		RecorderSpanNameFormatter("messaging", func(_ context.Context, _ string, _ ...any) string {
+			return "send"
+		}),
+		InstrumentationRecorder("custom", func(ctx context.Context, tracer oteltrace.Tracer, _ propagation.TextMapPropagator, spfmt InstrumentationSpanNameFormatter, op string, args ...any) (func(err error), bool) {
+			_, span := tracer.Start(ctx, spfmt(ctx, op, args...))
+
+			return func(_ error) { span.End() }, true
+		}, "custom-op"),
+	)
+
+	in.record(context.Background(), InstrumentationMessagePublish, &MessagePublish{Destination: "orders"})(nil)
+	in.record(context.Background(), "custom-op")(nil)
+
+	spans := recorder.Ended()
+	qt.Assert(t, qt.HasLen(spans, 2))
+	qt.Check(t, qt.Equals(spans[0].Name(), "send"))
+	qt.Check(t, qt.Equals(spans[1].Name(), "global custom-op"))
+}
@@ -24,8 +24,17 @@ var (
 // "url.full", "server.address", "network.protocol.name", "network.protocol.version",
 // "network.transport". The following attributes are returned if they
 // related values are defined in req: "server.port", "user_agent.original".
-func HTTPClientRequest(req *http.Request) []attribute.KeyValue {
-	return cc.ClientRequest(req)
+// The "peer.service" attribute is returned if the server host (with or without
+// port) is found in peerServices.
+func HTTPClientRequest(req *http.Request, peerServices map[string]string) []attribute.KeyValue {
+	return cc.ClientRequest(req, peerServices)
+}
+
+// HTTPClientRequestMetrics returns metric attributes for an HTTP request sent by
+// client: "http.request.method", "url.scheme", "server.address" and "server.port"
+// if it is not the default port of the scheme.
+func HTTPClientRequestMetrics(req *http.Request) []attribute.KeyValue {
+	return cc.ClientRequestMetrics(req)
 }
 
 // ClientResponse returns attributes for an HTTP response received by client.
@@ -56,6 +65,7 @@ type clientConv struct {
 	HTTPRequestHeaderContentLengthKey  attribute.Key
 	HTTPResponseStatusCodeKey          attribute.Key
 	HTTPResponseHeaderContentLengthKey attribute.Key
+	PeerServiceKey                     attribute.Key
 }
 
 var cc = &clientConv{
@@ -83,6 +93,7 @@ var cc = &clientConv{
 	HTTPRequestHeaderContentLengthKey:  attribute.Key("http.request.header.content-length"),
 	HTTPResponseStatusCodeKey:          semconv.HTTPResponseStatusCodeKey,
 	HTTPResponseHeaderContentLengthKey: attribute.Key("http.response.header.content-length"),
+	PeerServiceKey:                     semconv.PeerServiceKey,
 }
 
 // ClientRequest returns attributes for an HTTP request sent by client.
@@ -91,7 +102,9 @@ var cc = &clientConv{
 // "url.full", "server.address", "network.protocol.name", "network.protocol.version",
 // "network.transport". The following attributes are returned if they
 // related values are defined in req: "server.port", "user_agent.original".
-func (c *clientConv) ClientRequest(req *http.Request) []attribute.KeyValue {
+// The "peer.service" attribute is returned if the server host (with or without
+// port) is found in peerServices.
+func (c *clientConv) ClientRequest(req *http.Request, peerServices map[string]string) []attribute.KeyValue {
 	/*
 		The following semantic conventions are returned if present:
 		http.request.method        string
@@ -100,6 +113,7 @@ func (c *clientConv) ClientRequest(req *http.Request) []attribute.KeyValue {
 		server.address             string
 		server.port                int
 		user_agent.original        string
+		peer.service               string
 		network.protocol.name      string Note: always set as "http".
 		network.protocol.version   string Note: always set as "1.1".
 		network.transport          string Note: always set as "tcp".
@@ -149,6 +163,11 @@ func (c *clientConv) ClientRequest(req *http.Request) []attribute.KeyValue {
 		n++
 	}
 
+	peerService := c.peerService(peerServices, string(uri.Host()), host)
+	if peerService != "" {
+		n++
+	}
+
 	attrs := make([]attribute.KeyValue, 0, n+req.Header.Len())
 
 	attrs = append(attrs, c.method(string(req.Header.Method())))
@@ -172,6 +191,10 @@ func (c *clientConv) ClientRequest(req *http.Request) []attribute.KeyValue {
 		attrs = append(attrs, c.HTTPRequestHeaderContentLengthKey.Int(contentLen))
 	}
 
+	if peerService != "" {
+		attrs = append(attrs, c.PeerServiceKey.String(peerService))
+	}
+
 	req.Header.VisitAll(func(k, v []byte) {
 		key := strings.ToLower(string(k))
 		// Skip user agent and content length as they are already handled.
@@ -190,6 +213,42 @@ func (c *clientConv) ClientRequest(req *http.Request) []attribute.KeyValue {
 	return attrs
 }
 
+// ClientRequestMetrics returns metric attributes for an HTTP request sent by
+// client: "http.request.method", "url.scheme", "server.address" and "server.port"
+// if it is not the default port of the scheme.
+func (c *clientConv) ClientRequestMetrics(req *http.Request) []attribute.KeyValue {
+	uri := req.URI()
+
+	host, p := splitHostPort(string(uri.Host()))
+
+	isTLS := bytes.Equal(uri.Scheme(), []byte("https"))
+
+	attrs := make([]attribute.KeyValue, 0, 4)
+	attrs = append(attrs,
+		c.method(string(req.Header.Method())),
+		c.scheme(isTLS),
+		c.NetConv.ServerAddress(host),
+	)
+
+	if hostPort := requiredHTTPPort(isTLS, p); hostPort > 0 {
+		attrs = append(attrs, c.NetConv.ServerPort(hostPort))
+	}
+
+	return attrs
+}
+
+func (c *clientConv) peerService(peerServices map[string]string, hostPort, host string) string {
+	if len(peerServices) == 0 {
+		return ""
+	}
+
+	if svc, ok := peerServices[hostPort]; ok {
+		return svc
+	}
+
+	return peerServices[host]
+}
+
 func (c *clientConv) method(method string) attribute.KeyValue {
 	if method == "" {
 		return c.HTTPRequestMethodKey.String(fasthttp.MethodGet)
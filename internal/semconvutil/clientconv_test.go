@@ -0,0 +1,22 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package semconvutil
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestPeerService(t *testing.T) {
+	services := map[string]string{
+		"payments.internal":      "payments",
+		"payments.internal:8443": "payments-admin",
+	}
+
+	qt.Check(t, qt.Equals(cc.peerService(services, "payments.internal:8443", "payments.internal"), "payments-admin"))
+	qt.Check(t, qt.Equals(cc.peerService(services, "payments.internal:443", "payments.internal"), "payments"))
+	qt.Check(t, qt.Equals(cc.peerService(services, "example.com", "example.com"), ""))
+	qt.Check(t, qt.Equals(cc.peerService(nil, "payments.internal", "payments.internal"), ""))
+}
@@ -5,6 +5,7 @@ package semconvutil
 
 import (
 	"fmt"
+	"strings"
 
 	"azugo.io/azugo"
 	"github.com/valyala/fasthttp"
@@ -34,11 +35,31 @@ import (
 // "url.path", "url.full", "server.address". The following attributes are returned if they
 // related values are defined in req: "server.port", "network.peer.address",
 // "network.peer.port", "user_agent.original", "client.address",
-// "network.protocol.name", "network.protocol.version".
+// "network.protocol.name", "network.protocol.version", "http.request.body.size",
+// "network.forwarded.hops", "network.via".
 func HTTPServerRequest(ctx *azugo.Context) []attribute.KeyValue {
 	return hc.ServerRequest(ctx)
 }
 
+// HTTPServerRequestMetrics returns metric attributes for an HTTP request received
+// by a server: "http.request.method" and "url.scheme".
+func HTTPServerRequestMetrics(ctx *azugo.Context) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		hc.method(ctx.Method()),
+		hc.scheme(ctx.IsTLS()),
+	}
+}
+
+// HTTPServerResponse returns trace attributes for an HTTP response sent by a
+// server.
+//
+// The following attributes are always returned: "http.connection.close".
+// The following attributes are returned if they related values are defined:
+// "http.response.status_code", "http.response.body.size", "http.response.trailers".
+func HTTPServerResponse(ctx *azugo.Context) []attribute.KeyValue {
+	return hc.ServerResponse(ctx)
+}
+
 // HTTPServerStatus returns a span status code and message for an HTTP status code
 // value returned by a server. Status codes in the 400-499 range are not
 // returned as errors.
@@ -46,14 +67,21 @@ func HTTPServerStatus(code int) (codes.Code, string) {
 	return hc.ServerStatus(code)
 }
 
+// HTTPClientStatus returns a span status code and message for an HTTP status code
+// value received by a client. Status codes in the 400-599 range are returned
+// as errors.
+func HTTPClientStatus(code int) (codes.Code, string) {
+	return hc.ClientStatus(code)
+}
+
 // httpConv are the HTTP semantic convention attributes defined for a version
 // of the OpenTelemetry specification.
 type httpConv struct {
 	NetConv *netConv
 
-	HTTPRequestMethodKey attribute.Key
-	// HTTPRequestContentLengthKey  attribute.Key
-	// HTTPResponseContentLengthKey attribute.Key
+	HTTPRequestMethodKey      attribute.Key
+	HTTPRequestBodySizeKey    attribute.Key
+	HTTPResponseBodySizeKey   attribute.Key
 	HTTPRouteKey              attribute.Key
 	URLSchemeHTTP             attribute.KeyValue
 	URLSchemeHTTPS            attribute.KeyValue
@@ -61,14 +89,18 @@ type httpConv struct {
 	URLPathKey                attribute.Key
 	URLFullKey                attribute.Key
 	UserAgentOriginalKey      attribute.Key
+	NetworkForwardedHopsKey   attribute.Key
+	NetworkViaKey             attribute.Key
+	HTTPConnectionCloseKey    attribute.Key
+	HTTPResponseTrailersKey   attribute.Key
 }
 
 var hc = &httpConv{
 	NetConv: nc,
 
-	HTTPRequestMethodKey: semconv.HTTPRequestMethodKey,
-	// HTTPRequestContentLengthKey:  semconv.HTTPRequestContentLengthKey,
-	// HTTPResponseContentLengthKey: semconv.HTTPResponseContentLengthKey,
+	HTTPRequestMethodKey:      semconv.HTTPRequestMethodKey,
+	HTTPRequestBodySizeKey:    semconv.HTTPRequestBodySizeKey,
+	HTTPResponseBodySizeKey:   semconv.HTTPResponseBodySizeKey,
 	HTTPRouteKey:              semconv.HTTPRouteKey,
 	URLSchemeHTTP:             semconv.URLScheme("http"),
 	URLSchemeHTTPS:            semconv.URLScheme("https"),
@@ -76,6 +108,10 @@ var hc = &httpConv{
 	URLPathKey:                semconv.URLPathKey,
 	URLFullKey:                semconv.URLFullKey,
 	UserAgentOriginalKey:      semconv.UserAgentOriginalKey,
+	NetworkForwardedHopsKey:   attribute.Key("network.forwarded.hops"),
+	NetworkViaKey:             attribute.Key("network.via"),
+	HTTPConnectionCloseKey:    attribute.Key("http.connection.close"),
+	HTTPResponseTrailersKey:   attribute.Key("http.response.trailers"),
 }
 
 // ServerRequest returns attributes for an HTTP request received by a server.
@@ -98,7 +134,8 @@ var hc = &httpConv{
 // "url.path", "url.full", "server.address". The following attributes are returned if they
 // related values are defined in req: "server.port", "network.peer.address",
 // "network.peer.port", "user_agent.original", "client.address",
-// "network.protocol.name", "network.protocol.version".
+// "network.protocol.name", "network.protocol.version", "http.request.body.size",
+// "network.forwarded.hops", "network.via".
 func (c *httpConv) ServerRequest(ctx *azugo.Context) []attribute.KeyValue {
 	/*
 		The following semantic conventions are returned if present:
@@ -114,11 +151,13 @@ func (c *httpConv) ServerRequest(ctx *azugo.Context) []attribute.KeyValue {
 		network.protocol.version   string
 		url.path                   string Note: doesn't include the query parameter.
 		url.full                   string Note: doesn't include the query parameter.
+		http.request.body.size     int    Note: taken from the Content-Length header.
+		network.forwarded.hops     int    Note: number of proxies from the Forwarded and Via headers.
+		network.via                string[] Note: received-by identifiers from the Via header.
 
 		The following semantic conventions are not returned:
 		http.response.status_code             This requires the response.
-		http.request.header.content-length    This requires the len() of body, which can mutate it.
-		http.response.header.content-length   This requires the response.
+		http.response.body.size               This requires the response.
 		http.route                            This is not available.
 		network.local.address                 The request doesn't have access to the underlying socket.
 		network.local.port                    The request doesn't have access to the underlying socket.
@@ -173,6 +212,22 @@ func (c *httpConv) ServerRequest(ctx *azugo.Context) []attribute.KeyValue {
 		n++
 	}
 
+	bodySize := ctx.Request().Header.ContentLength()
+	if bodySize > 0 {
+		n++
+	}
+
+	via := parseVia(ctx.Request().Header.PeekAll(fasthttp.HeaderVia))
+
+	hops := max(forwardedHops(ctx.Request().Header.PeekAll(fasthttp.HeaderForwarded)), len(via))
+	if hops > 0 {
+		n++
+	}
+
+	if len(via) > 0 {
+		n++
+	}
+
 	attrs := make([]attribute.KeyValue, 0, n)
 
 	attrs = append(attrs, c.method(ctx.Method()))
@@ -222,6 +277,169 @@ func (c *httpConv) ServerRequest(ctx *azugo.Context) []attribute.KeyValue {
 		attrs = append(attrs, c.NetConv.NetworkProtocolVersion.String(protoVersion))
 	}
 
+	if bodySize > 0 {
+		attrs = append(attrs, c.HTTPRequestBodySizeKey.Int(bodySize))
+	}
+
+	if hops > 0 {
+		attrs = append(attrs, c.NetworkForwardedHopsKey.Int(hops))
+	}
+
+	if len(via) > 0 {
+		attrs = append(attrs, c.NetworkViaKey.StringSlice(via))
+	}
+
+	return attrs
+}
+
+const (
+	// maxForwardedHops limits number of forwarding proxies counted from the
+	// Forwarded and Via headers.
+	maxForwardedHops = 32
+	// maxViaEntries limits number of proxies reported from the Via headers.
+	maxViaEntries = 16
+)
+
+// forwardedHops returns number of forwarding proxies listed in the Forwarded headers.
+func forwardedHops(headers [][]byte) int {
+	hops := 0
+
+	for _, h := range headers {
+		splitList(string(h), func(elem string) bool {
+			if strings.TrimSpace(elem) != "" {
+				hops++
+			}
+
+			return hops < maxForwardedHops
+		})
+
+		if hops >= maxForwardedHops {
+			break
+		}
+	}
+
+	return hops
+}
+
+// parseVia returns received-by identifiers of the proxies listed in the Via headers.
+func parseVia(headers [][]byte) []string {
+	var via []string
+
+	for _, h := range headers {
+		splitList(string(h), func(entry string) bool {
+			// Entry format is "[protocol-name/]protocol-version received-by [comment]".
+			fields := strings.Fields(entry)
+			if len(fields) >= 2 {
+				via = append(via, fields[1])
+			}
+
+			return len(via) < maxViaEntries
+		})
+
+		if len(via) >= maxViaEntries {
+			break
+		}
+	}
+
+	return via
+}
+
+// splitList calls fn for each element of the comma-separated header value until
+// it returns false. Commas inside of quoted strings (RFC 7239) and comments
+// (RFC 9110) do not separate elements.
+func splitList(s string, fn func(elem string) bool) {
+	var (
+		start  int
+		quoted bool
+		escape bool
+		depth  int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case escape:
+			escape = false
+		case c == '\\' && (quoted || depth > 0):
+			escape = true
+		case c == '"' && depth == 0:
+			quoted = !quoted
+		case c == '(' && !quoted:
+			depth++
+		case c == ')' && !quoted && depth > 0:
+			depth--
+		case c == ',' && !quoted && depth == 0:
+			if !fn(s[start:i]) {
+				return
+			}
+
+			start = i + 1
+		}
+	}
+
+	fn(s[start:])
+}
+
+// ServerResponse returns attributes for an HTTP response sent by a server.
+//
+// The following attributes are always returned: "http.connection.close".
+// The following attributes are returned if they related values are defined:
+// "http.response.status_code", "http.response.body.size", "http.response.trailers".
+func (c *httpConv) ServerResponse(ctx *azugo.Context) []attribute.KeyValue {
+	/*
+		The following semantic conventions are returned if present:
+		http.response.status_code  int
+		http.response.body.size    int      Note: for streamed body taken from the Content-Length header.
+		http.response.trailers     []string Note: names of the trailers declared in the response.
+		http.connection.close      bool     Note: connection is closed after the response is sent.
+	*/
+	resp := ctx.Response()
+
+	n := 1
+
+	status := resp.StatusCode()
+	if status > 0 {
+		n++
+	}
+
+	var bodySize int
+	if resp.IsBodyStream() {
+		// Reading body stream would consume it.
+		bodySize = resp.Header.ContentLength()
+	} else {
+		bodySize = len(resp.Body())
+	}
+
+	if bodySize >= 0 {
+		n++
+	}
+
+	var trailers []string
+
+	resp.Header.VisitAllTrailer(func(value []byte) {
+		trailers = append(trailers, string(value))
+	})
+
+	if len(trailers) > 0 {
+		n++
+	}
+
+	attrs := make([]attribute.KeyValue, 0, n)
+
+	if status > 0 {
+		attrs = append(attrs, c.HTTPResponseStatusCodeKey.Int(status))
+	}
+
+	if bodySize >= 0 {
+		attrs = append(attrs, c.HTTPResponseBodySizeKey.Int(bodySize))
+	}
+
+	if len(trailers) > 0 {
+		attrs = append(attrs, c.HTTPResponseTrailersKey.StringSlice(trailers))
+	}
+
+	// Server closes the connection if either request or response asks for it.
+	attrs = append(attrs, c.HTTPConnectionCloseKey.Bool(resp.ConnectionClose() || ctx.Request().ConnectionClose()))
+
 	return attrs
 }
 
@@ -269,3 +487,18 @@ func (c *httpConv) ServerStatus(code int) (codes.Code, string) {
 
 	return codes.Unset, ""
 }
+
+// ClientStatus returns a span status code and message for an HTTP status code
+// value received by a client. Status codes in the 400-599 range are returned
+// as errors.
+func (c *httpConv) ClientStatus(code int) (codes.Code, string) {
+	if code < 100 || code >= 600 {
+		return codes.Error, fmt.Sprintf("Invalid HTTP status code %d", code)
+	}
+
+	if code >= 400 {
+		return codes.Error, ""
+	}
+
+	return codes.Unset, ""
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package semconvutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestForwardedHops(t *testing.T) {
+	tests := []struct {
+		headers  []string
+		expected int
+	}{
+		{headers: nil, expected: 0},
+		{headers: []string{"for=192.0.2.60;proto=http;by=203.0.113.43"}, expected: 1},
+		{headers: []string{"for=192.0.2.43, for=198.51.100.17"}, expected: 2},
+		{headers: []string{"for=192.0.2.43", "for=\"[2001:db8:cafe::17]:4711\", "}, expected: 2},
+		{headers: []string{"for=\"_a,b\";by=\"x\\\",y\", for=192.0.2.43"}, expected: 2},
+		{headers: []string{strings.Repeat("for=192.0.2.43,", 100)}, expected: maxForwardedHops},
+	}
+
+	for _, test := range tests {
+		qt.Check(t, qt.Equals(forwardedHops(toBytes(test.headers)), test.expected), qt.Commentf("headers: %v", test.headers))
+	}
+}
+
+func TestParseVia(t *testing.T) {
+	tests := []struct {
+		headers  []string
+		expected []string
+	}{
+		{headers: nil, expected: nil},
+		{headers: []string{"1.0 fred, 1.1 p.example.net"}, expected: []string{"fred", "p.example.net"}},
+		{headers: []string{"HTTP/1.1 proxy (Squid/4.1)", "2 edge"}, expected: []string{"proxy", "edge"}},
+		{headers: []string{"invalid"}, expected: nil},
+		{headers: []string{"1.1 proxy (Squid, 4.1), 1.1 edge"}, expected: []string{"proxy", "edge"}},
+	}
+
+	for _, test := range tests {
+		qt.Check(t, qt.DeepEquals(parseVia(toBytes(test.headers)), test.expected), qt.Commentf("headers: %v", test.headers))
+	}
+
+	qt.Check(t, qt.HasLen(parseVia(toBytes([]string{strings.Repeat("1.1 proxy, ", 100)})), maxViaEntries))
+}
+
+func toBytes(headers []string) [][]byte {
+	if headers == nil {
+		return nil
+	}
+
+	b := make([][]byte, 0, len(headers))
+	for _, h := range headers {
+		b = append(b, []byte(h))
+	}
+
+	return b
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code   int
+		server codes.Code
+		client codes.Code
+	}{
+		{code: 200, server: codes.Unset, client: codes.Unset},
+		{code: 302, server: codes.Unset, client: codes.Unset},
+		{code: 404, server: codes.Unset, client: codes.Error},
+		{code: 503, server: codes.Error, client: codes.Error},
+		{code: 99, server: codes.Error, client: codes.Error},
+	}
+
+	for _, test := range tests {
+		server, _ := HTTPServerStatus(test.code)
+		qt.Check(t, qt.Equals(server, test.server), qt.Commentf("server code: %d", test.code))
+
+		client, _ := HTTPClientStatus(test.code)
+		qt.Check(t, qt.Equals(client, test.client), qt.Commentf("client code: %d", test.code))
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+
+	"azugo.io/azugo"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// LoggerWithSpan returns logger with "trace.id" and "span.id" fields of the
+// current span in the context, so that logs written by background goroutines
+// started from the request are correlated to the right span. Logger is
+// returned unchanged if there is no valid span in the context.
+func LoggerWithSpan(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	sc := oteltrace.SpanContextFromContext(FromContext(ctx))
+	if !sc.IsValid() {
+		return logger
+	}
+
+	return logger.With(
+		zap.String("trace.id", sc.TraceID().String()),
+		zap.String("span.id", sc.SpanID().String()),
+	)
+}
+
+// RequestLogger returns request logger with "trace.id" and "span.id" fields of
+// the request server span and the authorized user claims configured by
+// "claim_attributes" configuration key, so that request logs are correlated to
+// the trace and the user the same way as the server span.
+func RequestLogger(ctx *azugo.Context) *zap.Logger {
+	logger := LoggerWithSpan(ctx, ctx.Log())
+
+	if state := requestStateFromContext(ctx); state != nil && state.claims != nil {
+		if fields := state.claims.logFields(ctx); len(fields) > 0 {
+			logger = logger.With(fields...)
+		}
+	}
+
+	return logger
+}
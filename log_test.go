@@ -0,0 +1,35 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggerWithSpan(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+
+	sc := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID: oteltrace.TraceID{1},
+		SpanID:  oteltrace.SpanID{2},
+	})
+
+	LoggerWithSpan(oteltrace.ContextWithSpanContext(context.Background(), sc), logger).Info("with span")
+	LoggerWithSpan(context.Background(), logger).Info("without span")
+
+	entries := logs.All()
+	qt.Assert(t, qt.HasLen(entries, 2))
+	qt.Check(t, qt.DeepEquals(entries[0].ContextMap(), map[string]any{
+		"trace.id": sc.TraceID().String(),
+		"span.id":  sc.SpanID().String(),
+	}))
+	qt.Check(t, qt.HasLen(entries[1].Context, 0))
+}
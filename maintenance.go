@@ -0,0 +1,86 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var maintenanceOperationKey = attribute.Key("azugo.maintenance.operation")
+
+// Maintenance runs recurring background maintenance work (for example cache
+// warming, expired entries cleanup or session garbage collection) in a new
+// trace with an internal span named after the operation and records its
+// duration in "azugo.maintenance.duration" metric. It allows to map background
+// CPU usage spikes to named operations.
+//
+// Only work wrapped by the application is traced, azugo internal cache cleanup
+// and warm-up loops do not provide hooks to be instrumented.
+//
+// Global tracer and meter providers are used.
+func Maintenance(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return globalProviders().Maintenance(ctx, name, fn)
+}
+
+// Maintenance runs recurring background maintenance work the same way as
+// Maintenance function using the tracer and meter providers of the providers.
+func (p *Providers) Maintenance(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	meter := p.MeterProvider.Meter(
+		ScopeName+"/maintenance",
+		metric.WithInstrumentationVersion(Version()),
+		metric.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
+
+	// Meter returns the same instrument for repeated calls, so it is not cached
+	// to follow the meter provider changes.
+	duration, err := meter.Float64Histogram(
+		"azugo.maintenance.duration",
+		metric.WithDescription("Duration of background maintenance operations."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	tracer := p.TracerProvider.Tracer(
+		ScopeName+"/maintenance",
+		trace.WithInstrumentationVersion(Version()),
+		trace.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
+
+	attrs := []attribute.KeyValue{maintenanceOperationKey.String(name)}
+
+	ctx, span := tracer.Start(ctx, "maintenance "+name,
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	start := time.Now()
+
+	err = fn(ctx)
+
+	if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+	}
+
+	if duration != nil {
+		duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+	}
+
+	return err
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationMessagePublish is the instrumentation operation for publishing
+// a message or domain event. The only argument must be *MessagePublish.
+//
+// Azugo does not publish messages itself, so the operation is reported by the
+// application code publishing messages through the application instrumenter,
+// the same way azugo HTTP client reports outgoing requests:
+//
+//	msg := &opentelemetry.MessagePublish{System: "kafka", Destination: "orders"}
+//	end := app.Instrumenter()(ctx, opentelemetry.InstrumentationMessagePublish, msg)
+//	err := producer.Publish(ctx, "orders", msg.Metadata, body)
+//	end(err)
+const InstrumentationMessagePublish = "messaging-publish"
+
+// MessagePublish describes message being published for the messaging recorder.
+type MessagePublish struct {
+	// System is the messaging system identifier, for example "kafka" or "rabbitmq".
+	System string
+	// Destination is the name of the topic, queue or event the message is published to.
+	Destination string
+	// ID of the message if known.
+	ID string
+	// BodySize of the message in bytes if known.
+	BodySize int
+	// Metadata of the message trace context is injected into. It is created if nil.
+	Metadata map[string]string
+}
+
+// InstrMessagePublish returns message being published from the instrumentation arguments.
+func InstrMessagePublish(op string, args ...any) (*MessagePublish, bool) {
+	if op != InstrumentationMessagePublish || len(args) != 1 {
+		return nil, false
+	}
+
+	msg, ok := args[0].(*MessagePublish)
+
+	return msg, ok && msg != nil
+}
+
+func messagingRecorder(ctx context.Context, tracer oteltrace.Tracer, propagator propagation.TextMapPropagator, spfmt InstrumentationSpanNameFormatter, op string, args ...any) (func(err error), bool) {
+	msg, ok := InstrMessagePublish(op, args...)
+	if !ok {
+		return nil, false
+	}
+
+	c := FromContext(ctx)
+
+	attrs := make([]attribute.KeyValue, 0, 6)
+	attrs = append(attrs,
+		semconv.MessagingOperationTypePublish,
+		semconv.MessagingOperationName("publish"),
+	)
+
+	if msg.System != "" {
+		attrs = append(attrs, semconv.MessagingSystemKey.String(msg.System))
+	}
+
+	if msg.Destination != "" {
+		attrs = append(attrs, semconv.MessagingDestinationName(msg.Destination))
+	}
+
+	if msg.ID != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(msg.ID))
+	}
+
+	if msg.BodySize > 0 {
+		attrs = append(attrs, semconv.MessagingMessageBodySize(msg.BodySize))
+	}
+
+	spanName := spfmt(ctx, op, args...)
+	if spanName == "" {
+		spanName = "publish"
+		if msg.Destination != "" {
+			spanName += " " + msg.Destination
+		}
+	}
+
+	//nolint:spancheck
+	c, span := tracer.Start(c, spanName,
+		oteltrace.WithAttributes(attrs...),
+		oteltrace.WithSpanKind(oteltrace.SpanKindProducer),
+	)
+
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]string)
+	}
+
+	propagator.Inject(c, propagation.MapCarrier(msg.Metadata))
+
+	//nolint:spancheck
+	return func(err error) {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+
+			span.RecordError(err, oteltrace.WithStackTrace(true))
+		}
+
+		span.End()
+	}, true
+}
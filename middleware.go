@@ -5,15 +5,22 @@ package opentelemetry
 
 import (
 	"context"
-	"errors"
+	"crypto/rand"
 	"fmt"
+	"net/url"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"azugo.io/opentelemetry/internal/semconvutil"
 
 	"azugo.io/azugo"
 	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
 	"go.opentelemetry.io/otel/trace"
@@ -25,12 +32,66 @@ const (
 	ScopeName = "azugo.io/opentelemetry"
 )
 
-const otelParentSpanContext = "__otelParentSpanContext"
+const (
+	otelParentSpanContext = "__otelParentSpanContext"
+	otelRequestState      = "__otelRequestState"
+)
+
+// requestState holds state of the traced request.
+type requestState struct {
+	clientAttempts clientAttempts
+	claims         *claimEnricher
+
+	mu                sync.Mutex
+	attrs             map[attribute.Key]attribute.Value
+	rateLimited       bool
+	rateLimitKeyClass string
+}
+
+func (s *requestState) deferAttributes(attrs []attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attrs == nil {
+		s.attrs = make(map[attribute.Key]attribute.Value, len(attrs))
+	}
+
+	for _, kv := range attrs {
+		s.attrs[kv.Key] = kv.Value
+	}
+}
+
+func (s *requestState) deferredAttributes() []attribute.KeyValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.attrs) == 0 {
+		return nil
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(s.attrs))
+	for k, v := range s.attrs {
+		attrs = append(attrs, attribute.KeyValue{Key: k, Value: v})
+	}
+
+	return attrs
+}
+
+func requestStateFromContext(ctx context.Context) *requestState {
+	c := azugo.RequestContext(ctx)
+	if c == nil {
+		return nil
+	}
+
+	s, _ := c.UserValue(otelRequestState).(*requestState)
+
+	return s
+}
 
 // middleware sets up a handler to start tracing the incoming
 // requests.  The service parameter should describe the name of the
 // (virtual) server handling the request.
-func middleware(opts ...Option) func(azugo.RequestHandler) azugo.RequestHandler {
+func middleware(config *Configuration, opts ...Option) func(azugo.RequestHandler) azugo.RequestHandler {
 	cfg := traceConfig(opts...)
 
 	tracer := cfg.TracerProvider.Tracer(
@@ -39,49 +100,152 @@ func middleware(opts ...Option) func(azugo.RequestHandler) azugo.RequestHandler
 		trace.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
 	)
 
+	meter := cfg.MeterProvider.Meter(
+		ScopeName+"/router",
+		metric.WithInstrumentationVersion(Version()),
+		metric.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
+
+	activeRequests, err := meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithDescription("Number of active HTTP server requests."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	duration := newDurationHistogram(meter,
+		"http.server.request.duration",
+		"Duration of HTTP server requests.",
+		cfg.durationBuckets,
+	)
+
+	var unmatchedRoutes *unmatchedRouteReporter
+
+	if cfg.ReportUnmatchedRoutes {
+		unmatchedRoutes, err = newUnmatchedRouteReporter(meter, cfg.cardinality)
+		if err != nil {
+			otel.Handle(err)
+		}
+	}
+
 	return func(h azugo.RequestHandler) azugo.RequestHandler {
 		t := traceware{
 			tracer:                 tracer,
 			propagators:            cfg.Propagators,
 			routeSpanNameFormatter: cfg.routeSpanNameFormatter,
+			spanStatusFromResponse: cfg.spanStatusFromResponse,
 			instrSpanNameFormatter: cfg.instrSpanNameFormatter,
 			publicEndpoint:         cfg.PublicEndpoint,
 			publicEndpointFn:       cfg.PublicEndpointFn,
+			filteredPropagation:    cfg.FilteredPropagation,
+			unmatchedRoutes:        unmatchedRoutes,
+			activeRequests:         activeRequests,
+			duration:               duration,
+			unmatchedMethodName:    cfg.unmatchedMethodName,
+			claims:                 newClaimEnricher(config, cfg.userClaim),
+			edgeTimingHeaders:      cfg.edgeTimingHeaders,
+			tenants:                newTenantResolver(config, cfg.userClaim),
+			attrs:                  cfg.serverAttributes,
+			cardinality:            cfg.cardinality,
 			filters:                cfg.Filters,
+			skip:                   newSkipList(config),
+			requestBaggage:         cfg.requestBaggage,
+			enrichers:              cfg.spanEnrichers,
+			endHooks:               cfg.spanEndHooks,
 		}
 
 		return t.handle(h)
 	}
 }
 
-func panicHandler(ctx *azugo.Context, val any) {
-	ctx.Log().Error("Unhandled error", zap.Any("error", val))
+const (
+	// maxGoroutineDumpSize limits size of the goroutine dump recorded on panic.
+	maxGoroutineDumpSize   = 64 * 1024
+	goroutineDumpEventName = "goroutine.dump"
+)
 
-	c := FromContext(ctx)
+var (
+	goroutineDumpKey      = attribute.Key("goroutine.dump")
+	goroutineDumpTruncKey = attribute.Key("goroutine.dump.truncated")
+	panicKey              = attribute.Key("panic")
+	routeMatchedKey       = attribute.Key("azugo.route.matched")
+)
+
+// newPanicHandler returns router panic handler that records the panic on the
+// server span, counts it in "http.server.panics" metric and optionally attaches
+// truncated dump of all goroutines.
+func newPanicHandler(config *Configuration, opts ...Option) func(ctx *azugo.Context, val any) {
+	cfg := traceConfig(opts...)
 
-	span := trace.SpanFromContext(c)
+	meter := cfg.MeterProvider.Meter(
+		ScopeName+"/router",
+		metric.WithInstrumentationVersion(Version()),
+		metric.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
 
-	var err error
-	if e, ok := val.(error); ok {
-		err = e
-	} else if e, ok := val.(string); ok {
-		err = errors.New(e)
+	panics, err := meter.Int64Counter(
+		"http.server.panics",
+		metric.WithDescription("Number of requests that panicked."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
 	}
 
-	if span.SpanContext().IsValid() && span.IsRecording() {
-		span.SetAttributes(semconv.HTTPResponseStatusCode(fasthttp.StatusInternalServerError))
-		span.SetStatus(codes.Error, fmt.Sprintf("%v", val))
+	return func(ctx *azugo.Context, val any) {
+		if panics != nil {
+			attrs := []attribute.KeyValue{semconv.HTTPRequestMethodKey.String(ctx.Method())}
+			if route := ctx.RouterPath(); route != "" {
+				attrs = append(attrs, semconv.HTTPRoute(route))
+			}
 
-		if err != nil {
-			span.RecordError(err, trace.WithStackTrace(true))
+			panics.Add(ctx, 1, metric.WithAttributes(cfg.cardinality.filter(ctx, attrs)...))
 		}
 
+		if config.PanicGoroutineDump {
+			span := trace.SpanFromContext(FromContext(ctx))
+			if span.IsRecording() {
+				buf := make([]byte, maxGoroutineDumpSize)
+				n := runtime.Stack(buf, true)
+
+				span.AddEvent(goroutineDumpEventName, trace.WithAttributes(
+					goroutineDumpKey.String(string(buf[:n])),
+					goroutineDumpTruncKey.Bool(n == len(buf)),
+				))
+			}
+		}
+
+		panicHandler(ctx, val)
+	}
+}
+
+func panicHandler(ctx *azugo.Context, val any) {
+	span := trace.SpanFromContext(FromContext(ctx))
+
+	RequestLogger(ctx).Error("Unhandled error", zap.Any("error", val))
+
+	err, ok := val.(error)
+	if !ok {
+		err = fmt.Errorf("%v", val)
+	}
+
+	if span.SpanContext().IsValid() && span.IsRecording() {
+		span.SetAttributes(
+			semconv.HTTPResponseStatusCode(fasthttp.StatusInternalServerError),
+			panicKey.Bool(true),
+		)
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err, trace.WithStackTrace(true), trace.WithAttributes(semconv.ExceptionEscaped(true)))
+
 		span.End()
 	}
 
-	if err != nil {
+	switch val.(type) {
+	case error, string:
 		ctx.Error(err)
-	} else {
+	default:
 		ctx.StatusCode(fasthttp.StatusInternalServerError)
 	}
 }
@@ -90,10 +254,25 @@ type traceware struct {
 	tracer                 trace.Tracer
 	propagators            propagation.TextMapPropagator
 	routeSpanNameFormatter func(ctx *azugo.Context, routeName string) string
+	spanStatusFromResponse func(ctx *azugo.Context, code int) (codes.Code, string)
 	instrSpanNameFormatter func(ctx context.Context, op string, args ...interface{}) string
 	publicEndpoint         bool
 	publicEndpointFn       func(ctx *azugo.Context) bool
+	filteredPropagation    bool
+	unmatchedRoutes        *unmatchedRouteReporter
+	activeRequests         metric.Int64UpDownCounter
+	duration               metric.Float64Histogram
+	unmatchedMethodName    bool
+	claims                 *claimEnricher
+	edgeTimingHeaders      []string
+	tenants                *tenantResolver
+	attrs                  []attribute.KeyValue
+	cardinality            *cardinalityGuard
 	filters                []Filter
+	skip                   *skipList
+	requestBaggage         RequestBaggage
+	enrichers              []SpanEnricher
+	endHooks               []OnSpanEnd
 }
 
 // defaultRouteSpanNameFunc just reuses the route name as the span name.
@@ -107,13 +286,132 @@ func defaultRouteSpanNameFunc(ctx *azugo.Context, routeName string) string {
 	return s.String()
 }
 
+// newRouteSpanNameFunc returns route span name function that prefixes the route
+// name with the application base path and collapses identifiers in the request
+// path for requests without route template.
+func newRouteSpanNameFunc(basePath, collapseIDs bool) RouteSpanNameFormatter {
+	return func(ctx *azugo.Context, routeName string) string {
+		if collapseIDs {
+			if r := ctx.RouterPath(); r == "" || strings.HasSuffix(r, ":*}") {
+				routeName = collapsePathIDs(ctx.Path())
+			}
+		}
+
+		if basePath {
+			if u, err := url.Parse(ctx.BaseURL()); err == nil && u.Path != "" && u.Path != "/" {
+				routeName = strings.TrimSuffix(u.Path, "/") + routeName
+			}
+		}
+
+		return defaultRouteSpanNameFunc(ctx, routeName)
+	}
+}
+
+// collapsePathIDs replaces numeric and UUID path segments with "{id}".
+func collapsePathIDs(p string) string {
+	segments := strings.Split(p, "/")
+
+	for i, s := range segments {
+		if isNumeric(s) || isUUID(s) {
+			segments[i] = "{id}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// defaultSpanStatusFromResponse uses semantic conventions to determine span status.
+func defaultSpanStatusFromResponse(_ *azugo.Context, code int) (codes.Code, string) {
+	return semconvutil.HTTPServerStatus(code)
+}
+
+// untraced passes the request through to the handler without tracing it.
+func (tw traceware) untraced(ctx *azugo.Context, next azugo.RequestHandler) {
+	if tw.filteredPropagation {
+		ctx.SetUserValue(otelParentSpanContext, tw.untracedContext(ctx))
+	}
+
+	next(ctx)
+}
+
+// untracedContext returns context to be used as a parent for outgoing requests
+// made while handling request that is not traced. Incoming trace context is used
+// if present, otherwise new not sampled root span context is generated so that
+// downstream services do not record traces of the filtered request.
+func (tw traceware) untracedContext(ctx *azugo.Context) context.Context {
+	c := tw.propagators.Extract(ctx, azugoHeaderCarrier(ctx))
+
+	if tw.requestBaggage != nil {
+		c = withRequestBaggage(c, tw.requestBaggage(ctx))
+	}
+
+	if trace.SpanContextFromContext(c).IsValid() {
+		return c
+	}
+
+	var (
+		traceID trace.TraceID
+		spanID  trace.SpanID
+	)
+
+	_, _ = rand.Read(traceID[:])
+	_, _ = rand.Read(spanID[:])
+
+	return trace.ContextWithRemoteSpanContext(c, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+}
+
 // handle implements the azugo.RequestHandler interface. It does the actual
 // tracing of the request.
 func (tw traceware) handle(next azugo.RequestHandler) func(ctx *azugo.Context) {
 	return func(ctx *azugo.Context) {
+		if tw.skip.skip(ctx) {
+			// Skipped requests neither create spans nor propagate trace context
+			next(ctx)
+
+			return
+		}
+
 		if val, ok := ctx.UserValue("__log_request").(bool); !ok || !val {
 			// If the request is not to be logged, simply pass through to the handler
-			next(ctx)
+			tw.untraced(ctx, next)
 
 			return
 		}
@@ -121,7 +419,7 @@ func (tw traceware) handle(next azugo.RequestHandler) func(ctx *azugo.Context) {
 		for _, f := range tw.filters {
 			if !f(ctx) {
 				// Simply pass through to the handler if a filter rejects the request
-				next(ctx)
+				tw.untraced(ctx, next)
 
 				return
 			}
@@ -132,11 +430,38 @@ func (tw traceware) handle(next azugo.RequestHandler) func(ctx *azugo.Context) {
 			ctx = ac
 		}
 
+		if tw.requestBaggage != nil {
+			c = withRequestBaggage(c, tw.requestBaggage(ctx))
+		}
+
 		opts := []trace.SpanStartOption{
-			trace.WithAttributes(semconvutil.HTTPServerRequest(ctx)...),
+			trace.WithAttributes(tw.cardinality.filter(ctx, semconvutil.HTTPServerRequest(ctx))...),
 			trace.WithSpanKind(trace.SpanKindServer),
 		}
 
+		if len(tw.attrs) > 0 {
+			opts = append(opts, trace.WithAttributes(tw.attrs...))
+		}
+
+		if tw.tenants != nil {
+			if tenant := tw.tenants.tenant(ctx); tenant != "" {
+				opts = append(opts, trace.WithAttributes(tw.cardinality.filter(ctx, []attribute.KeyValue{tenantIDKey.String(tenant)})...))
+			}
+		}
+
+		now := time.Now()
+
+		// Time spent in the middlewares before tracing started is not part of the span duration.
+		if delay := now.Sub(ctx.Context().Time()); delay > 0 {
+			opts = append(opts, trace.WithAttributes(requestDelayKey.Float64(delay.Seconds())))
+		}
+
+		if len(tw.edgeTimingHeaders) > 0 {
+			if attrs := edgeLatencyAttributes(ctx, tw.edgeTimingHeaders, now); len(attrs) > 0 {
+				opts = append(opts, trace.WithAttributes(attrs...))
+			}
+		}
+
 		if tw.publicEndpoint || (tw.publicEndpointFn != nil && tw.publicEndpointFn(ctx)) {
 			opts = append(opts, trace.WithNewRoot())
 			// Linking incoming span context if any for public endpoint.
@@ -145,28 +470,89 @@ func (tw traceware) handle(next azugo.RequestHandler) func(ctx *azugo.Context) {
 			}
 		}
 
+		var (
+			spanName string
+			rAttr    []attribute.KeyValue
+		)
+
 		routeStr := ctx.RouterPath()
 		if routeStr == "" {
 			routeStr = "route not found"
+
+			opts = append(opts, trace.WithAttributes(routeMatchedKey.Bool(false)))
+
+			if tw.unmatchedMethodName {
+				spanName = "HTTP " + ctx.Method()
+			}
+
+			if tw.unmatchedRoutes != nil {
+				tw.unmatchedRoutes.report(ctx)
+			}
 		} else {
-			rAttr := semconv.HTTPRoute(routeStr)
-			opts = append(opts, trace.WithAttributes(rAttr))
+			rAttr = tw.cardinality.filter(ctx, []attribute.KeyValue{semconv.HTTPRoute(routeStr)})
+			opts = append(opts, trace.WithAttributes(rAttr...))
+		}
+
+		if spanName == "" {
+			spanName = tw.routeSpanNameFormatter(ctx, routeStr)
+		}
+
+		if tw.activeRequests != nil {
+			attrs := metric.WithAttributes(semconvutil.HTTPServerRequestMetrics(ctx)...)
+
+			tw.activeRequests.Add(ctx, 1, attrs)
+			// Deferred to be decremented also if the handler panics.
+			defer tw.activeRequests.Add(ctx, -1, attrs)
 		}
 
-		spanName := tw.routeSpanNameFormatter(ctx, routeStr)
 		c, span := tw.tracer.Start(c, spanName, opts...)
 
+		state := &requestState{claims: tw.claims}
+
 		ctx.SetUserValue(otelParentSpanContext, c)
+		ctx.SetUserValue(otelRequestState, state)
+
+		if span.IsRecording() {
+			for _, f := range tw.enrichers {
+				f(ctx, span)
+			}
+		}
 
 		next(ctx)
 
-		status := ctx.Response().StatusCode()
-		if status > 0 {
-			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		span.SetAttributes(semconvutil.HTTPServerResponse(ctx)...)
+
+		var rateLimited bool
+
+		if span.IsRecording() {
+			if attrs := state.deferredAttributes(); len(attrs) > 0 {
+				span.SetAttributes(tw.cardinality.filter(ctx, attrs)...)
+			}
+
+			rateLimited = recordRateLimit(ctx, span, state)
+
+			if tw.claims != nil {
+				span.SetAttributes(tw.cardinality.filter(ctx, tw.claims.attributes(ctx))...)
+			}
 		}
 
-		span.SetStatus(semconvutil.HTTPServerStatus(status))
+		// Rate limited requests are expected rejections, so span status is left unset.
+		if !rateLimited {
+			span.SetStatus(tw.spanStatusFromResponse(ctx, ctx.Response().StatusCode()))
+		}
+
+		if span.IsRecording() {
+			for _, f := range tw.endHooks {
+				f(ctx, span)
+			}
+		}
 
 		span.End()
+
+		mAttrs := semconvutil.HTTPServerRequestMetrics(ctx)
+		mAttrs = append(mAttrs, semconv.HTTPResponseStatusCode(ctx.Response().StatusCode()))
+		mAttrs = append(mAttrs, rAttr...)
+
+		tw.duration.Record(ctx, time.Since(now).Seconds(), metric.WithAttributes(mAttrs...))
 	}
 }
@@ -0,0 +1,45 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestCollapsePathIDs(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/", expected: "/"},
+		{path: "/api/v1/user/123", expected: "/api/v1/user/{id}"},
+		{path: "/api/v1/user/0b9c3c8e-4f0a-4b8e-9d2c-6b1f9e2a7c11/orders/42", expected: "/api/v1/user/{id}/orders/{id}"},
+		{path: "/api/v2/user/me", expected: "/api/v2/user/me"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			qt.Check(t, qt.Equals(collapsePathIDs(test.path), test.expected))
+		})
+	}
+}
+
+func TestSkipList(t *testing.T) {
+	qt.Check(t, qt.IsNil(newSkipList(&Configuration{})))
+
+	l := newSkipList(&Configuration{
+		SkipPaths:      []string{"/healthz", "/probe/*"},
+		SkipUserAgents: []string{"kube-probe/"},
+	})
+
+	qt.Check(t, qt.IsTrue(l.matchPath("/healthz")))
+	qt.Check(t, qt.IsTrue(l.matchPath("/probe/ready")))
+	qt.Check(t, qt.IsFalse(l.matchPath("/api/healthz")))
+	qt.Check(t, qt.IsFalse(l.matchPath("/healthz/live")))
+	qt.Check(t, qt.IsTrue(l.matchUserAgent("kube-probe/1.29")))
+	qt.Check(t, qt.IsFalse(l.matchUserAgent("")))
+	qt.Check(t, qt.IsFalse(l.matchUserAgent("Mozilla/5.0")))
+}
@@ -0,0 +1,259 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// defaultNPlusOneThreshold is the default number of sibling spans with the same
+// name that are collapsed into a summary span.
+const defaultNPlusOneThreshold = 10
+
+// defaultNPlusOneMaxSpans is the default limit of sampled spans kept in memory
+// until the local root span of the trace ends.
+const defaultNPlusOneMaxSpans = 10000
+
+var (
+	nplusoneDetectedKey = attribute.Key("nplusone.detected")
+	nplusoneCountKey    = attribute.Key("nplusone.count")
+	nplusoneDurationKey = attribute.Key("nplusone.duration")
+)
+
+type siblingKey struct {
+	parent oteltrace.SpanID
+	name   string
+}
+
+// nplusoneProcessor buffers sampled spans until the local root span of the trace
+// ends and collapses groups of sibling spans with the same name into a single
+// summary span with count and total duration attributes. Groups with spans that
+// have child spans are not collapsed. Spans ending after their local root span
+// has ended are exported right away.
+type nplusoneProcessor struct {
+	next      []trace.SpanProcessor
+	threshold int
+	maxSpans  int
+
+	mu       sync.Mutex
+	active   map[oteltrace.TraceID]int
+	traces   map[oteltrace.TraceID][]trace.ReadOnlySpan
+	buffered int
+}
+
+func newNPlusOneProcessor(config *NPlusOne, next ...trace.SpanProcessor) *nplusoneProcessor {
+	threshold := config.Threshold
+	if threshold <= 0 {
+		threshold = defaultNPlusOneThreshold
+	}
+
+	maxSpans := config.MaxBufferedSpans
+	if maxSpans <= 0 {
+		maxSpans = defaultNPlusOneMaxSpans
+	}
+
+	return &nplusoneProcessor{
+		next:      next,
+		threshold: threshold,
+		maxSpans:  maxSpans,
+		active:    make(map[oteltrace.TraceID]int),
+		traces:    make(map[oteltrace.TraceID][]trace.ReadOnlySpan),
+	}
+}
+
+func (p *nplusoneProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	if s.SpanContext().IsSampled() && isLocalRoot(s) {
+		p.mu.Lock()
+		p.active[s.SpanContext().TraceID()]++
+		p.mu.Unlock()
+	}
+
+	for _, n := range p.next {
+		n.OnStart(parent, s)
+	}
+}
+
+func (p *nplusoneProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		p.export(s)
+
+		return
+	}
+
+	traceID := s.SpanContext().TraceID()
+
+	p.mu.Lock()
+
+	if !isLocalRoot(s) {
+		if p.active[traceID] == 0 || p.buffered >= p.maxSpans {
+			p.mu.Unlock()
+			p.export(s)
+
+			return
+		}
+
+		p.traces[traceID] = append(p.traces[traceID], s)
+		p.buffered++
+
+		p.mu.Unlock()
+
+		return
+	}
+
+	if p.active[traceID]--; p.active[traceID] <= 0 {
+		delete(p.active, traceID)
+	}
+
+	spans := p.traces[traceID]
+	delete(p.traces, traceID)
+	p.buffered -= len(spans)
+
+	p.mu.Unlock()
+
+	spans, detected := p.collapse(spans)
+	if detected {
+		s = &attributedSpan{ReadOnlySpan: s, attrs: []attribute.KeyValue{nplusoneDetectedKey.Bool(true)}}
+	}
+
+	p.export(append(spans, s)...)
+}
+
+// collapse returns spans with groups of repeated siblings replaced by summary spans.
+func (p *nplusoneProcessor) collapse(spans []trace.ReadOnlySpan) ([]trace.ReadOnlySpan, bool) {
+	if len(spans) < p.threshold {
+		return spans, false
+	}
+
+	parents := make(map[oteltrace.SpanID]struct{}, len(spans))
+	groups := make(map[siblingKey][]int)
+
+	for i, s := range spans {
+		parents[s.Parent().SpanID()] = struct{}{}
+
+		key := siblingKey{parent: s.Parent().SpanID(), name: s.Name()}
+		groups[key] = append(groups[key], i)
+	}
+
+	dropped := make(map[int]struct{})
+	summaries := make(map[int]trace.ReadOnlySpan)
+
+	for _, idx := range groups {
+		if len(idx) < p.threshold {
+			continue
+		}
+
+		var total time.Duration
+
+		collapsible := true
+
+		for _, i := range idx {
+			if _, ok := parents[spans[i].SpanContext().SpanID()]; ok {
+				collapsible = false
+
+				break
+			}
+
+			total += spans[i].EndTime().Sub(spans[i].StartTime())
+		}
+
+		if !collapsible {
+			continue
+		}
+
+		summaries[idx[0]] = &attributedSpan{
+			ReadOnlySpan: spans[idx[0]],
+			attrs: []attribute.KeyValue{
+				nplusoneDetectedKey.Bool(true),
+				nplusoneCountKey.Int(len(idx)),
+				nplusoneDurationKey.Float64(total.Seconds()),
+			},
+		}
+
+		for _, i := range idx[1:] {
+			dropped[i] = struct{}{}
+		}
+	}
+
+	if len(summaries) == 0 {
+		return spans, false
+	}
+
+	result := make([]trace.ReadOnlySpan, 0, len(spans)-len(dropped))
+
+	for i, s := range spans {
+		if _, ok := dropped[i]; ok {
+			continue
+		}
+
+		if summary, ok := summaries[i]; ok {
+			s = summary
+		}
+
+		result = append(result, s)
+	}
+
+	return result, true
+}
+
+func (p *nplusoneProcessor) export(spans ...trace.ReadOnlySpan) {
+	for _, s := range spans {
+		for _, n := range p.next {
+			n.OnEnd(s)
+		}
+	}
+}
+
+func (p *nplusoneProcessor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	traces := p.traces
+	p.active = make(map[oteltrace.TraceID]int)
+	p.traces = make(map[oteltrace.TraceID][]trace.ReadOnlySpan)
+	p.buffered = 0
+	p.mu.Unlock()
+
+	// Spans of traces that have not ended are exported as they are.
+	for _, spans := range traces {
+		p.export(spans...)
+	}
+
+	var err error
+
+	for _, n := range p.next {
+		err = errors.Join(err, n.Shutdown(ctx))
+	}
+
+	return err
+}
+
+func (p *nplusoneProcessor) ForceFlush(ctx context.Context) error {
+	var err error
+
+	for _, n := range p.next {
+		err = errors.Join(err, n.ForceFlush(ctx))
+	}
+
+	return err
+}
+
+// attributedSpan is a read-only span view with additional attributes.
+type attributedSpan struct {
+	trace.ReadOnlySpan
+	attrs []attribute.KeyValue
+}
+
+func (s *attributedSpan) Attributes() []attribute.KeyValue {
+	attrs := s.ReadOnlySpan.Attributes()
+
+	result := make([]attribute.KeyValue, 0, len(attrs)+len(s.attrs))
+	result = append(result, attrs...)
+
+	return append(result, s.attrs...)
+}
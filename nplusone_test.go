@@ -0,0 +1,68 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNPlusOneProcessor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	tracer := trace.NewTracerProvider(
+		trace.WithSpanProcessor(newNPlusOneProcessor(&NPlusOne{Enabled: true, Threshold: 3}, recorder)),
+	).Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "GET /orders")
+
+	for range 5 {
+		_, span := tracer.Start(ctx, "GET cache")
+		span.End()
+	}
+
+	_, span := tracer.Start(ctx, "SELECT orders")
+	span.End()
+
+	root.End()
+
+	spans := recorder.Ended()
+	qt.Assert(t, qt.HasLen(spans, 3))
+
+	qt.Check(t, qt.Equals(spans[0].Name(), "GET cache"))
+
+	attrs := spans[0].Attributes()
+	qt.Assert(t, qt.HasLen(attrs, 3))
+	qt.Check(t, qt.Equals(attrs[0], nplusoneDetectedKey.Bool(true)))
+	qt.Check(t, qt.Equals(attrs[1], nplusoneCountKey.Int(5)))
+
+	qt.Check(t, qt.Equals(spans[1].Name(), "SELECT orders"))
+	qt.Check(t, qt.HasLen(spans[1].Attributes(), 0))
+
+	qt.Check(t, qt.Equals(spans[2].Name(), "GET /orders"))
+	qt.Check(t, qt.Equals(spans[2].Attributes()[0], nplusoneDetectedKey.Bool(true)))
+}
+
+func TestNPlusOneProcessorLateChild(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	processor := newNPlusOneProcessor(&NPlusOne{Enabled: true}, recorder)
+
+	tracer := trace.NewTracerProvider(
+		trace.WithSpanProcessor(processor),
+	).Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "GET /orders")
+	_, span := tracer.Start(ctx, "GET cache")
+	root.End()
+	span.End()
+
+	qt.Check(t, qt.HasLen(recorder.Ended(), 2))
+	qt.Check(t, qt.HasLen(processor.traces, 0))
+	qt.Check(t, qt.HasLen(processor.active, 0))
+	qt.Check(t, qt.Equals(processor.buffered, 0))
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+// Package opentelemetrytest provides in-memory telemetry recorder and assertion
+// helpers for testing instrumentation of the Azugo applications.
+package opentelemetrytest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"azugo.io/opentelemetry"
+
+	"azugo.io/azugo"
+	"azugo.io/core"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Recorder records telemetry in memory instead of exporting it.
+type Recorder struct {
+	exporter      *tracetest.InMemoryExporter
+	provider      *trace.TracerProvider
+	reader        *metric.ManualReader
+	meterProvider *metric.MeterProvider
+}
+
+// New creates new in-memory telemetry recorder.
+func New() *Recorder {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := metric.NewManualReader()
+
+	return &Recorder{
+		exporter: exporter,
+		provider: trace.NewTracerProvider(
+			trace.WithSyncer(exporter),
+		),
+		reader:        reader,
+		meterProvider: metric.NewMeterProvider(metric.WithReader(reader)),
+	}
+}
+
+// Use OpenTelemetry for tracing in Azugo application recording all telemetry
+// in memory. Returned tasker can be passed to the functions configuring
+// telemetry of the application, for example opentelemetry.Route.
+func Use(app *azugo.App, opts ...opentelemetry.Option) (*Recorder, core.Tasker, error) {
+	r := New()
+
+	opts = append(opts[:len(opts):len(opts)], r.Options()...)
+
+	t, err := opentelemetry.Use(app, nil, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, t, nil
+}
+
+// Options returns options to use recorder tracer and meter providers.
+func (r *Recorder) Options() []opentelemetry.Option {
+	return []opentelemetry.Option{
+		opentelemetry.TracerProvider(r.provider),
+		opentelemetry.MeterProvider(r.meterProvider),
+	}
+}
+
+// TracerProvider returns tracer provider that records spans in memory.
+func (r *Recorder) TracerProvider() *trace.TracerProvider {
+	return r.provider
+}
+
+// MeterProvider returns meter provider that records metrics in memory.
+func (r *Recorder) MeterProvider() *metric.MeterProvider {
+	return r.meterProvider
+}
+
+// Metrics returns metrics recorded so far. Recorded values are cumulative and
+// are not cleared by ResetSpans.
+func (r *Recorder) Metrics() metricdata.ResourceMetrics {
+	var rm metricdata.ResourceMetrics
+
+	_ = r.reader.Collect(context.Background(), &rm)
+
+	return rm
+}
+
+// FindMetric returns recorded metric with the name.
+func (r *Recorder) FindMetric(name string) (metricdata.Metrics, bool) {
+	for _, sm := range r.Metrics().ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+
+	return metricdata.Metrics{}, false
+}
+
+// Spans returns all ended spans recorded so far.
+func (r *Recorder) Spans() tracetest.SpanStubs {
+	return r.exporter.GetSpans()
+}
+
+// ResetSpans clears all recorded spans.
+func (r *Recorder) ResetSpans() {
+	r.exporter.Reset()
+}
+
+// FindSpan returns first recorded span with the name that has all provided attributes.
+func (r *Recorder) FindSpan(name string, attrs ...attribute.KeyValue) (tracetest.SpanStub, bool) {
+	for _, s := range r.Spans() {
+		if s.Name == name && hasAttributes(s.Attributes, attrs) {
+			return s, true
+		}
+	}
+
+	return tracetest.SpanStub{}, false
+}
+
+// AssertSpan asserts that span with the name and all provided attributes has been recorded.
+func (r *Recorder) AssertSpan(t testing.TB, name string, attrs ...attribute.KeyValue) tracetest.SpanStub {
+	t.Helper()
+
+	s, ok := r.FindSpan(name, attrs...)
+	if !ok {
+		t.Errorf("span %q with attributes %s not found, recorded spans:\n%s", name, formatAttributes(attrs), r.formatSpans())
+	}
+
+	return s
+}
+
+// AssertNoSpan asserts that no span with the name has been recorded.
+func (r *Recorder) AssertNoSpan(t testing.TB, name string) {
+	t.Helper()
+
+	if _, ok := r.FindSpan(name); ok {
+		t.Errorf("unexpected span %q recorded", name)
+	}
+}
+
+func hasAttributes(attrs []attribute.KeyValue, want []attribute.KeyValue) bool {
+	set := attribute.NewSet(attrs...)
+
+	for _, kv := range want {
+		v, ok := set.Value(kv.Key)
+		if !ok || v != kv.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func formatAttributes(attrs []attribute.KeyValue) string {
+	var s strings.Builder
+
+	s.WriteByte('[')
+
+	for i, kv := range attrs {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+
+		s.WriteString(string(kv.Key))
+		s.WriteByte('=')
+		s.WriteString(kv.Value.Emit())
+	}
+
+	s.WriteByte(']')
+
+	return s.String()
+}
+
+func (r *Recorder) formatSpans() string {
+	var s strings.Builder
+
+	for _, span := range r.Spans() {
+		fmt.Fprintf(&s, "  %s %s\n", span.Name, formatAttributes(span.Attributes))
+	}
+
+	return s.String()
+}
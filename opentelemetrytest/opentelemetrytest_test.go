@@ -0,0 +1,50 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetrytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestRecorderFindSpan(t *testing.T) {
+	r := New()
+
+	_, span := r.TracerProvider().Tracer("test").Start(context.Background(), "GET /user/{id}")
+	span.SetAttributes(
+		attribute.String("http.route", "/user/{id}"),
+		attribute.Int("http.response.status_code", 200),
+	)
+	span.End()
+
+	s := r.AssertSpan(t, "GET /user/{id}", attribute.Int("http.response.status_code", 200))
+	qt.Check(t, qt.Equals(s.Name, "GET /user/{id}"))
+
+	_, ok := r.FindSpan("GET /user/{id}", attribute.Int("http.response.status_code", 404))
+	qt.Check(t, qt.IsFalse(ok))
+
+	r.AssertNoSpan(t, "GET /")
+
+	r.ResetSpans()
+	qt.Check(t, qt.HasLen(r.Spans(), 0))
+}
+
+func TestRecorderFindMetric(t *testing.T) {
+	r := New()
+
+	counter, err := r.MeterProvider().Meter("test").Int64Counter("http.server.panics")
+	qt.Assert(t, qt.IsNil(err))
+
+	counter.Add(context.Background(), 1)
+
+	m, ok := r.FindMetric("http.server.panics")
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Check(t, qt.Equals(m.Name, "http.server.panics"))
+
+	_, ok = r.FindMetric("http.server.request.duration")
+	qt.Check(t, qt.IsFalse(ok))
+}
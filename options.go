@@ -5,21 +5,53 @@ package opentelemetry
 
 import (
 	"context"
+	"time"
 
 	"azugo.io/azugo"
+	"azugo.io/core/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 // otelcfg is used to configure the mux middleware.
 type otelcfg struct {
 	TracerProvider         oteltrace.TracerProvider
+	MeterProvider          metric.MeterProvider
 	Propagators            propagation.TextMapPropagator
 	routeSpanNameFormatter RouteSpanNameFormatter
+	routeSpanNameBasePath  bool
+	routeSpanNameCollapse  bool
+	unmatchedMethodName    bool
+	spanStatusFromResponse SpanStatusFromResponse
+	clientRequestSigner    ClientRequestSigner
+	userClaim              UserClaim
 	instrSpanNameFormatter InstrumentationSpanNameFormatter
+	recorderSpanNames      map[string]InstrumentationSpanNameFormatter
 	instrRecorders         []instrRecorder
+	edgeTimingHeaders      []string
+	cardinality            *cardinalityGuard
+	spanProcessors         []sdktrace.SpanProcessor
+	traceExporter          sdktrace.SpanExporter
+	serverAttributes       []attribute.KeyValue
+	clientAttributes       []attribute.KeyValue
+	clockOffset            func() time.Duration
+	peerServices           map[string]string
+	durationBuckets        []float64
+	payloadMaxBytes        int
+	payloadRedactor        ClientPayloadRedactor
+	cacheEventThreshold    time.Duration
+	requestBaggage         RequestBaggage
+	spanEnrichers          []SpanEnricher
+	spanEndHooks           []OnSpanEnd
 	PublicEndpoint         bool
 	PublicEndpointFn       PublicEndpointFilter
+	FilteredPropagation    bool
+	IsolatedProviders      bool
+	ReportUnmatchedRoutes  bool
 	Filters                []Filter
 }
 
@@ -67,6 +99,31 @@ func (f PublicEndpointFilter) apply(c *otelcfg) {
 	c.PublicEndpointFn = f
 }
 
+// FilteredPropagation configures the Handler to still propagate trace context
+// to the outgoing requests made while handling requests that are not traced
+// (for example rejected by filters). Incoming trace context is used if present,
+// otherwise a new not sampled span context is generated. Downstream services
+// then share the same trace ID, which can be used to correlate their logs, but
+// do not sample the request as it is not recorded here either.
+type FilteredPropagation bool
+
+func (p FilteredPropagation) apply(c *otelcfg) {
+	c.FilteredPropagation = bool(p)
+}
+
+// IsolatedProviders configures Use not to set the global tracer provider,
+// propagator and error handler, so that multiple applications (or tests) in
+// the same process can have independent telemetry pipelines. Request tracing
+// and instrumentation recorders use the configured providers, while RunTask,
+// StartStream, Maintenance, ForceFlush, FlushOnPanic and FatalHook functions
+// keep using the global ones; use the methods of the providers returned by
+// ProvidersFrom instead.
+type IsolatedProviders bool
+
+func (p IsolatedProviders) apply(c *otelcfg) {
+	c.IsolatedProviders = bool(p)
+}
+
 // TextMapPropagator specifies propagators to use for extracting
 // information from the HTTP requests. If none are specified, global
 // ones will be used.
@@ -88,6 +145,118 @@ func TracerProvider(provider oteltrace.TracerProvider) Option {
 	})
 }
 
+// MeterProvider specifies a meter provider to use for creating a meter.
+// If none is specified, the global provider is used.
+func MeterProvider(provider metric.MeterProvider) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		if provider != nil {
+			cfg.MeterProvider = provider
+		}
+	})
+}
+
+// SpanProcessor registers additional span processor (for example attribute
+// scrubbing or secondary exporter) on the tracer provider created by Use.
+// Processors are called after the configured exporter in the order they are
+// added. It is ignored if TracerProvider option is provided.
+func SpanProcessor(processor sdktrace.SpanProcessor) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		if processor != nil {
+			cfg.spanProcessors = append(cfg.spanProcessors, processor)
+		}
+	})
+}
+
+// TraceExporter specifies span exporter (for example Zipkin, Kafka or in-memory
+// exporter in tests) to use instead of the exporter configured by the "exporter"
+// configuration key. Sampling, scrubbing, instrumentation and other configuration
+// still applies. It is ignored if TracerProvider option is provided.
+func TraceExporter(exporter sdktrace.SpanExporter) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		if exporter != nil {
+			cfg.traceExporter = exporter
+		}
+	})
+}
+
+// CacheAsEvents configures cache recorder to record successful cache operations
+// faster than the threshold as events on the parent span instead of child spans,
+// so that many fast cache lookups do not flood the trace. Slow and failed
+// operations are still recorded as spans.
+func CacheAsEvents(threshold time.Duration) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		cfg.cacheEventThreshold = threshold
+	})
+}
+
+// ServerAttributes specifies static attributes added to every server span
+// (for example service mesh or zone name).
+func ServerAttributes(attrs ...attribute.KeyValue) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		cfg.serverAttributes = append(cfg.serverAttributes, attrs...)
+	})
+}
+
+// ClientAttributes specifies static attributes added to every HTTP client span.
+func ClientAttributes(attrs ...attribute.KeyValue) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		cfg.clientAttributes = append(cfg.clientAttributes, attrs...)
+	})
+}
+
+// RequestBaggage returns baggage entries (for example tenant id of the
+// authenticated user) to be added to the baggage of the request, so that they
+// are propagated to the outgoing requests made while handling it.
+//
+// User is only available if it has been authorized before the tracing middleware.
+type RequestBaggage func(ctx *azugo.Context) map[string]string
+
+func (f RequestBaggage) apply(c *otelcfg) {
+	c.requestBaggage = f
+}
+
+// PeerServiceMap maps outgoing HTTP request server host (with or without port)
+// to service name set as "peer.service" attribute of the client spans, so that
+// service maps show friendly dependency names instead of host names.
+func PeerServiceMap(services map[string]string) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		if cfg.peerServices == nil {
+			cfg.peerServices = make(map[string]string, len(services))
+		}
+
+		for host, svc := range services {
+			cfg.peerServices[host] = svc
+		}
+	})
+}
+
+// DurationHistogramBuckets specifies explicit bucket boundaries in seconds of
+// the HTTP server and client request duration histograms.
+func DurationHistogramBuckets(bounds ...float64) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		cfg.durationBuckets = bounds
+	})
+}
+
+// ReportUnmatchedRoutes configures the Handler to count requests that do not
+// match any registered route and so are traced without "http.route" attribute.
+// Additionally a rate-limited warning is logged to help finding unregistered
+// endpoints that are being called.
+type ReportUnmatchedRoutes bool
+
+func (r ReportUnmatchedRoutes) apply(c *otelcfg) {
+	c.ReportUnmatchedRoutes = bool(r)
+}
+
+// UnmatchedRouteMethodSpanName configures the Handler to name spans of requests
+// that do not match any registered route "HTTP <METHOD>" (for example "HTTP GET")
+// instead of using the route span name formatter, to keep span name cardinality low.
+type UnmatchedRouteMethodSpanName bool
+
+func (b UnmatchedRouteMethodSpanName) apply(c *otelcfg) {
+	c.unmatchedMethodName = bool(b)
+}
+
 // RouteSpanNameFormatter specifies a function to use for generating a custom span
 // name. By default, the route name (path template or regexp) is used. The route
 // name is provided so you can use it in the span name without needing to
@@ -98,6 +267,73 @@ func (f RouteSpanNameFormatter) apply(c *otelcfg) {
 	c.routeSpanNameFormatter = f
 }
 
+// RouteSpanNameBasePath configures the default route span name formatter to
+// prefix the route name with the application base path, so that the same
+// service mounted under different paths can be distinguished.
+type RouteSpanNameBasePath bool
+
+func (b RouteSpanNameBasePath) apply(c *otelcfg) {
+	c.routeSpanNameBasePath = bool(b)
+}
+
+// RouteSpanNameCollapseIDs configures the default route span name formatter to
+// use the request path with numeric and UUID path segments replaced with "{id}"
+// (for example "GET /api/v1/user/{id}") for requests that did not match a route
+// template or matched a catch-all route.
+type RouteSpanNameCollapseIDs bool
+
+func (b RouteSpanNameCollapseIDs) apply(c *otelcfg) {
+	c.routeSpanNameCollapse = bool(b)
+}
+
+// SpanStatusFromResponse specifies a function to use for determining the server span
+// status from the response. By default, only status codes in the 500-599 range and
+// invalid status codes are treated as errors.
+type SpanStatusFromResponse func(ctx *azugo.Context, code int) (codes.Code, string)
+
+func (f SpanStatusFromResponse) apply(c *otelcfg) {
+	c.spanStatusFromResponse = f
+}
+
+// ClientRequestSigner specifies a function to sign outgoing HTTP client requests.
+// It is guaranteed to be called after the trace context headers have been
+// injected into the request, so they can be included in the signature. Requests
+// are signed even if telemetry is disabled.
+type ClientRequestSigner func(ctx context.Context, req *http.Request)
+
+func (f ClientRequestSigner) apply(c *otelcfg) {
+	c.clientRequestSigner = f
+}
+
+// UserClaim specifies a function to get values of the authorized user claim.
+// It is required to add attributes from the user claims configured by the
+// "claim_attributes" configuration key to the server spans.
+type UserClaim func(user azugo.User, name string) []string
+
+func (f UserClaim) apply(c *otelcfg) {
+	c.userClaim = f
+}
+
+// SpanEnricher specifies a function to add domain attributes (for example tenant,
+// account tier or feature flags) to every recorded server span. It is called after
+// the span is started and before the request handler. Multiple enrichers can be
+// provided and are called in the order they are added.
+type SpanEnricher func(ctx *azugo.Context, span oteltrace.Span)
+
+func (f SpanEnricher) apply(c *otelcfg) {
+	c.spanEnrichers = append(c.spanEnrichers, f)
+}
+
+// OnSpanEnd specifies a function to add attributes derived from the response
+// (for example business error code or bytes streamed) to every recorded server
+// span. It is called after the request handler right before the span is ended.
+// Multiple hooks can be provided and are called in the order they are added.
+type OnSpanEnd func(ctx *azugo.Context, span oteltrace.Span)
+
+func (f OnSpanEnd) apply(c *otelcfg) {
+	c.spanEndHooks = append(c.spanEndHooks, f)
+}
+
 // InstrumentationSpanNameFormatter specifies a function to use for generating a custom span
 // name. By default, the span name is formatted based on the operation type and the arguments.
 // If the provided function returns an empty string, the default span name will be used.
@@ -107,6 +343,23 @@ func (f InstrumentationSpanNameFormatter) apply(c *otelcfg) {
 	c.instrSpanNameFormatter = f
 }
 
+// RecorderSpanNameFormatter specifies a function to use for generating a custom span
+// name only for the spans of the named instrumentation recorder (for example "cache").
+// It takes precedence over InstrumentationSpanNameFormatter.
+func RecorderSpanNameFormatter(name string, formatter InstrumentationSpanNameFormatter) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		if formatter == nil {
+			return
+		}
+
+		if cfg.recorderSpanNames == nil {
+			cfg.recorderSpanNames = make(map[string]InstrumentationSpanNameFormatter)
+		}
+
+		cfg.recorderSpanNames[name] = formatter
+	})
+}
+
 // InstrumentationRecorderFunc specifies a function to use for handling instrumentation events.
 // The function should return a function that can be used to finish the span and a boolean
 // indicating if specific instrumentation has been recorded.
@@ -0,0 +1,94 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	clientRequestBodyEventName  = "http.request.body"
+	clientResponseBodyEventName = "http.response.body"
+)
+
+var (
+	payloadContentKey   = attribute.Key("http.body.content")
+	payloadTruncatedKey = attribute.Key("http.body.truncated")
+)
+
+// CaptureClientPayloads configures HTTP client recorder to add JSON and XML request
+// and response bodies truncated to maxBytes as span events to debug integration
+// failures. Bodies may contain sensitive data, so ClientPayloadRedactor should be
+// provided to redact them.
+func CaptureClientPayloads(maxBytes int) Option {
+	return optionFunc(func(cfg *otelcfg) {
+		cfg.payloadMaxBytes = maxBytes
+	})
+}
+
+// ClientPayloadRedactor specifies a function to redact captured HTTP client request
+// and response bodies before they are truncated and added to the span. The function
+// must not modify the provided body.
+type ClientPayloadRedactor func(contentType string, body []byte) []byte
+
+func (f ClientPayloadRedactor) apply(c *otelcfg) {
+	c.payloadRedactor = f
+}
+
+// payloadCapture adds captured payloads as span events.
+type payloadCapture struct {
+	maxBytes int
+	redact   ClientPayloadRedactor
+}
+
+func newPayloadCapture(cfg *otelcfg) *payloadCapture {
+	if cfg.payloadMaxBytes <= 0 {
+		return nil
+	}
+
+	return &payloadCapture{
+		maxBytes: cfg.payloadMaxBytes,
+		redact:   cfg.payloadRedactor,
+	}
+}
+
+func (p *payloadCapture) record(span oteltrace.Span, name, contentType string, body []byte) {
+	if p == nil || len(body) == 0 || !span.IsRecording() || !capturedContentType(contentType) {
+		return
+	}
+
+	if p.redact != nil {
+		body = p.redact(contentType, body)
+	}
+
+	truncated := len(body) > p.maxBytes
+	if truncated {
+		// Body is truncated on the rune boundary not to split multi-byte character.
+		n := p.maxBytes
+		for n > 0 && !utf8.RuneStart(body[n]) {
+			n--
+		}
+
+		body = body[:n]
+	}
+
+	// Exporters reject strings with invalid UTF-8 failing the whole batch.
+	span.AddEvent(name, oteltrace.WithAttributes(
+		payloadContentKey.String(strings.ToValidUTF8(string(body), "\uFFFD")),
+		payloadTruncatedKey.Bool(truncated),
+	))
+}
+
+// capturedContentType returns true for JSON and XML media types.
+func capturedContentType(contentType string) bool {
+	mt, _, _ := strings.Cut(contentType, ";")
+	mt = strings.ToLower(strings.TrimSpace(mt))
+
+	return strings.HasSuffix(mt, "/json") || strings.HasSuffix(mt, "+json") ||
+		strings.HasSuffix(mt, "/xml") || strings.HasSuffix(mt, "+xml")
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPayloadCapture(t *testing.T) {
+	qt.Check(t, qt.IsNil(newPayloadCapture(&otelcfg{})))
+
+	recorder := tracetest.NewSpanRecorder()
+	_, span := trace.NewTracerProvider(trace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "test")
+
+	p := newPayloadCapture(&otelcfg{
+		payloadMaxBytes: 16,
+		payloadRedactor: func(_ string, body []byte) []byte {
+			return bytes.ReplaceAll(body, []byte("secret"), []byte("***"))
+		},
+	})
+
+	p.record(span, clientRequestBodyEventName, "application/json; charset=utf-8", []byte(`{"password":"secret","name":"test"}`))
+	p.record(span, clientResponseBodyEventName, "image/png", []byte("png"))
+	span.End()
+
+	spans := recorder.Ended()
+	qt.Assert(t, qt.HasLen(spans, 1))
+
+	events := spans[0].Events()
+	qt.Assert(t, qt.HasLen(events, 1))
+	qt.Check(t, qt.Equals(events[0].Name, clientRequestBodyEventName))
+	qt.Check(t, qt.Equals(events[0].Attributes[0], payloadContentKey.String(`{"password":"***`)))
+	qt.Check(t, qt.Equals(events[0].Attributes[1], payloadTruncatedKey.Bool(true)))
+}
+
+func TestPayloadCaptureUTF8(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	_, span := trace.NewTracerProvider(trace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "test")
+
+	p := newPayloadCapture(&otelcfg{payloadMaxBytes: 11})
+
+	p.record(span, clientResponseBodyEventName, "application/json", []byte(`{"name":"Jānis"}`))
+	p.record(span, clientResponseBodyEventName, "application/json", []byte("{\"a\":\"\xff\"}"))
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	qt.Assert(t, qt.HasLen(events, 2))
+	qt.Check(t, qt.Equals(events[0].Attributes[0], payloadContentKey.String(`{"name":"J`)))
+	qt.Check(t, qt.Equals(events[1].Attributes[0], payloadContentKey.String("{\"a\":\"\uFFFD\"}")))
+}
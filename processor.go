@@ -0,0 +1,53 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"encoding/binary"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// ratioSpanProcessor passes only spans of the configured ratio of traces to
+// the wrapped span processor.
+type ratioSpanProcessor struct {
+	trace.SpanProcessor
+
+	bound uint64
+}
+
+// newRatioSpanProcessor returns span processor that passes only the ratio of traces
+// to the wrapped processor. Traces are selected the same way as by the trace ID ratio
+// based sampler, so all spans of the same trace are either passed or dropped. Zero
+// ratio drops all spans.
+func newRatioSpanProcessor(p trace.SpanProcessor, ratio float64) trace.SpanProcessor {
+	if ratio >= 1 {
+		return p
+	}
+
+	ratio = max(ratio, 0)
+
+	return &ratioSpanProcessor{
+		SpanProcessor: p,
+		bound:         uint64(ratio * (1 << 63)),
+	}
+}
+
+func (p *ratioSpanProcessor) pass(traceID oteltrace.TraceID) bool {
+	return binary.BigEndian.Uint64(traceID[8:16])>>1 < p.bound
+}
+
+func (p *ratioSpanProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	if p.pass(s.SpanContext().TraceID()) {
+		p.SpanProcessor.OnStart(parent, s)
+	}
+}
+
+func (p *ratioSpanProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if p.pass(s.SpanContext().TraceID()) {
+		p.SpanProcessor.OnEnd(s)
+	}
+}
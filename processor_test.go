@@ -0,0 +1,42 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRatioSpanProcessor(t *testing.T) {
+	tests := []struct {
+		ratio    *float64
+		expected int
+	}{
+		{ratio: nil, expected: 10},
+		{ratio: ptr(1.0), expected: 10},
+		{ratio: ptr(0.0), expected: 0},
+	}
+
+	for _, test := range tests {
+		recorder := tracetest.NewSpanRecorder()
+		tracer := trace.NewTracerProvider(
+			trace.WithSpanProcessor(newRatioSpanProcessor(recorder, ratioOrAll(test.ratio))),
+		).Tracer("test")
+
+		for range 10 {
+			_, span := tracer.Start(context.Background(), "test")
+			span.End()
+		}
+
+		qt.Check(t, qt.HasLen(recorder.Ended(), test.expected))
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
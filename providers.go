@@ -0,0 +1,56 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"azugo.io/core"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Providers are the telemetry providers used by the instrumentation.
+type Providers struct {
+	// TracerProvider used to create tracers.
+	TracerProvider oteltrace.TracerProvider
+	// MeterProvider used to create meters.
+	MeterProvider metric.MeterProvider
+	// Propagator used to propagate trace context and baggage.
+	Propagator propagation.TextMapPropagator
+}
+
+// ProvidersFrom returns the providers configured by Use, so that applications
+// and libraries can create additional tracers and meters from the same providers
+// instead of relying on globals. No-op providers are returned if OpenTelemetry
+// is disabled.
+func ProvidersFrom(t core.Tasker) *Providers {
+	s, ok := t.(*setup)
+	if !ok || s.instr == nil {
+		return &Providers{
+			TracerProvider: tracenoop.NewTracerProvider(),
+			MeterProvider:  metricnoop.NewMeterProvider(),
+			Propagator:     propagation.NewCompositeTextMapPropagator(),
+		}
+	}
+
+	cfg := s.instr.cfg
+
+	return &Providers{
+		TracerProvider: cfg.TracerProvider,
+		MeterProvider:  cfg.MeterProvider,
+		Propagator:     cfg.Propagators,
+	}
+}
+
+// globalProviders returns the global providers.
+func globalProviders() *Providers {
+	return &Providers{
+		TracerProvider: otel.GetTracerProvider(),
+		MeterProvider:  otel.GetMeterProvider(),
+		Propagator:     otel.GetTextMapPropagator(),
+	}
+}
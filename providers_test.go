@@ -0,0 +1,45 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestProvidersFrom(t *testing.T) {
+	tp := trace.NewTracerProvider()
+
+	p := ProvidersFrom(&setup{instr: newInstrumentation(TracerProvider(tp))})
+	qt.Check(t, qt.Equals[any](p.TracerProvider, tp))
+	qt.Check(t, qt.IsNotNil(p.MeterProvider))
+	qt.Check(t, qt.IsNotNil(p.Propagator))
+
+	p = ProvidersFrom(&noop{})
+	qt.Check(t, qt.IsNotNil(p.TracerProvider))
+	qt.Check(t, qt.HasLen(p.Propagator.Fields(), 0))
+}
+
+func TestProvidersMaintenance(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	p := &Providers{
+		TracerProvider: trace.NewTracerProvider(trace.WithSpanProcessor(recorder)),
+		MeterProvider:  metricnoop.NewMeterProvider(),
+	}
+
+	err := p.Maintenance(context.Background(), "cleanup", func(_ context.Context) error {
+		return nil
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	spans := recorder.Ended()
+	qt.Assert(t, qt.HasLen(spans, 1))
+	qt.Check(t, qt.Equals(spans[0].Name(), "maintenance cleanup"))
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+
+	"azugo.io/azugo"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const rateLimitExceededEvent = "rate_limit.exceeded"
+
+var (
+	rateLimitExceededKey = attribute.Key("rate_limit.exceeded")
+	rateLimitKeyClassKey = attribute.Key("rate_limit.key_class")
+)
+
+// RateLimitExceeded marks the request as rejected by the rate limiter.
+//
+// Key class should describe the kind of the key request was limited by
+// (for example "ip", "user" or "api_key") and not the key value itself.
+// Requests responded with 429 status code are marked as rate limited
+// automatically without a key class. Span status of rate limited requests
+// is left unset, so they are never marked as errors.
+func RateLimitExceeded(ctx context.Context, keyClass string) {
+	state := requestStateFromContext(ctx)
+	if state == nil {
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.rateLimited = true
+	state.rateLimitKeyClass = keyClass
+}
+
+func (s *requestState) rateLimit() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rateLimitKeyClass, s.rateLimited
+}
+
+// recordRateLimit records rate limit exceeded event and reports whether the
+// request was rate limited.
+func recordRateLimit(ctx *azugo.Context, span trace.Span, state *requestState) bool {
+	keyClass, limited := state.rateLimit()
+	if !limited && ctx.Response().StatusCode() != fasthttp.StatusTooManyRequests {
+		return false
+	}
+
+	span.SetAttributes(rateLimitExceededKey.Bool(true))
+
+	var opts []trace.EventOption
+	if keyClass != "" {
+		opts = append(opts, trace.WithAttributes(rateLimitKeyClassKey.String(keyClass)))
+	}
+
+	span.AddEvent(rateLimitExceededEvent, opts...)
+
+	return true
+}
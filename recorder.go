@@ -0,0 +1,68 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// RecorderOption configures options of the spans started by the instrumentation recorder.
+type RecorderOption func(opts []oteltrace.SpanStartOption) []oteltrace.SpanStartOption
+
+// WithSpanKind sets span kind of the spans started by the recorder.
+func WithSpanKind(kind oteltrace.SpanKind) RecorderOption {
+	return func(opts []oteltrace.SpanStartOption) []oteltrace.SpanStartOption {
+		return append(opts, oteltrace.WithSpanKind(kind))
+	}
+}
+
+// WithAttributes adds static attributes to the spans started by the recorder.
+func WithAttributes(attrs ...attribute.KeyValue) RecorderOption {
+	return func(opts []oteltrace.SpanStartOption) []oteltrace.SpanStartOption {
+		return append(opts, oteltrace.WithAttributes(attrs...))
+	}
+}
+
+// WithRecorderOptions returns recorder that starts spans with the span kind
+// and static attributes. Options take precedence over the ones passed by the
+// recorder when starting spans, so span kind and attributes with the same key
+// set by the recorder are overridden:
+//
+//	opentelemetry.InstrumentationRecorder("queue",
+//		opentelemetry.WithRecorderOptions(queueRecorder,
+//			opentelemetry.WithSpanKind(trace.SpanKindConsumer),
+//			opentelemetry.WithAttributes(semconv.MessagingSystemKey.String("nats")),
+//		),
+//		"queue-receive",
+//	)
+func WithRecorderOptions(recorder InstrumentationRecorderFunc, opts ...RecorderOption) InstrumentationRecorderFunc {
+	var overrides []oteltrace.SpanStartOption
+	for _, opt := range opts {
+		overrides = opt(overrides)
+	}
+
+	return func(ctx context.Context, tracer oteltrace.Tracer, propagator propagation.TextMapPropagator, spfmt InstrumentationSpanNameFormatter, op string, args ...any) (func(err error), bool) {
+		return recorder(ctx, &overridesTracer{Tracer: tracer, overrides: overrides}, propagator, spfmt, op, args...)
+	}
+}
+
+// overridesTracer starts spans with the start options overriding the ones
+// passed by the caller.
+type overridesTracer struct {
+	oteltrace.Tracer
+
+	overrides []oteltrace.SpanStartOption
+}
+
+func (t *overridesTracer) Start(ctx context.Context, spanName string, opts ...oteltrace.SpanStartOption) (context.Context, oteltrace.Span) {
+	o := make([]oteltrace.SpanStartOption, 0, len(opts)+len(t.overrides))
+	o = append(o, opts...)
+	o = append(o, t.overrides...)
+
+	return t.Tracer.Start(ctx, spanName, o...)
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestWithRecorderOptions(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := trace.NewTracerProvider(trace.WithSpanProcessor(recorder)).Tracer("test")
+
+	rec := WithRecorderOptions(func(ctx context.Context, tracer oteltrace.Tracer, _ propagation.TextMapPropagator, _ InstrumentationSpanNameFormatter, op string, _ ...any) (func(err error), bool) {
+		_, span := tracer.Start(ctx, op,
+			oteltrace.WithSpanKind(oteltrace.SpanKindInternal),
+			oteltrace.WithAttributes(attribute.String("queue.name", "orders")),
+		)
+
+		return func(_ error) { span.End() }, true
+	},
+		WithSpanKind(oteltrace.SpanKindConsumer),
+		WithAttributes(attribute.String("messaging.system", "nats")),
+	)
+
+	end, ok := rec(context.Background(), tracer, propagation.TraceContext{}, defaultInstrSpanNameFormatter, "queue-receive")
+	qt.Assert(t, qt.IsTrue(ok))
+	end(nil)
+
+	spans := recorder.Ended()
+	qt.Assert(t, qt.HasLen(spans, 1))
+	qt.Check(t, qt.Equals(spans[0].SpanKind(), oteltrace.SpanKindConsumer))
+
+	attrs := spans[0].Attributes()
+	qt.Assert(t, qt.HasLen(attrs, 2))
+	qt.Check(t, qt.Equals(attrs[0], attribute.String("queue.name", "orders")))
+	qt.Check(t, qt.Equals(attrs[1], attribute.String("messaging.system", "nats")))
+}
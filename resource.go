@@ -0,0 +1,156 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"azugo.io/azugo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.uber.org/zap"
+)
+
+// newResource returns resource describing the application, process and
+// the telemetry SDK. It must be used by all providers.
+//
+// Attributes are merged in the following order, latter taking precedence:
+// detected cloud attributes, application and system attributes, attributes
+// from "resource_attributes" configuration key and explicitly configured
+// service name.
+func newResource(app *azugo.App, config *Configuration) *resource.Resource {
+	attrs := make([]attribute.KeyValue, 0, 12)
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = app.AppName
+	}
+
+	attrs = append(attrs,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(app.AppVer),
+		semconv.DeploymentEnvironmentName(config.DeploymentEnvironment.Resolve(string(app.Env()))),
+	)
+
+	// Add system information attributes.
+	sysattrs, instanceID := sysinfoAttrs()
+
+	if instanceID != "" {
+		attrs = append(attrs, semconv.ServiceInstanceID(instanceID))
+	}
+
+	attrs = append(attrs, sysattrs...)
+
+	attrs = append(attrs,
+		semconv.ProcessPID(os.Getpid()),
+		semconv.ProcessRuntimeName("go"),
+		semconv.ProcessRuntimeVersion(runtime.Version()),
+		semconv.ProcessRuntimeDescription("go compiler"),
+		semconv.TelemetrySDKName("opentelemetry"),
+		semconv.TelemetrySDKLanguageGo,
+		semconv.TelemetrySDKVersion(sdk.Version()),
+	)
+
+	res := resource.NewWithAttributes(semconv.SchemaURL, attrs...)
+
+	if len(config.ResourceDetectors) > 0 {
+		detected, err := detectResource(app.BackgroundContext(), config.ResourceDetectors)
+		if err != nil {
+			app.Log().Warn("Open Telemetry resource detection error", zap.Error(err))
+		}
+
+		res = mergeResource(app, detected, res)
+	}
+
+	if config.ResourceAttributes != "" {
+		rattrs, err := parseResourceAttributes(config.ResourceAttributes)
+		if err != nil {
+			app.Log().Warn("Open Telemetry invalid resource attributes", zap.Error(err))
+		}
+
+		if config.ServiceName != "" {
+			rattrs = append(rattrs, semconv.ServiceName(config.ServiceName))
+		}
+
+		res = mergeResource(app, res, resource.NewWithAttributes(semconv.SchemaURL, rattrs...))
+	}
+
+	return res
+}
+
+// mergeResource merges resources with attributes of b taking precedence.
+// In case of conflicting schema URLs resource a is returned.
+func mergeResource(app *azugo.App, a, b *resource.Resource) *resource.Resource {
+	merged, err := resource.Merge(a, b)
+	if err != nil {
+		app.Log().Warn("Open Telemetry resource merge error", zap.Error(err))
+
+		return a
+	}
+
+	return merged
+}
+
+// parseResourceAttributes parses comma-separated list of key=value pairs
+// in the OTEL_RESOURCE_ATTRIBUTES format. Values can be percent-encoded.
+//
+// Valid attributes are returned along with the error if some of the pairs are invalid.
+func parseResourceAttributes(s string) ([]attribute.KeyValue, error) {
+	pairs := strings.Split(s, ",")
+	attrs := make([]attribute.KeyValue, 0, len(pairs))
+
+	var errs error
+
+	for _, p := range pairs {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(p, "=")
+		k = strings.TrimSpace(k)
+
+		if !ok || k == "" {
+			errs = errors.Join(errs, fmt.Errorf("missing value for resource attribute: %q", p))
+
+			continue
+		}
+
+		val, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid value for resource attribute %q: %w", k, err))
+
+			continue
+		}
+
+		attrs = append(attrs, attribute.String(k, val))
+	}
+
+	return attrs, errs
+}
+
+// mapAttributes returns string attributes from the key/value map sorted by key.
+func mapAttributes(m map[string]string) []attribute.KeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, attribute.String(k, m[k]))
+	}
+
+	return attrs
+}
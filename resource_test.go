@@ -0,0 +1,19 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestParseResourceAttributes(t *testing.T) {
+	attrs, err := parseResourceAttributes("team=core, region = eu%2Cwest ,,invalid")
+	qt.Check(t, qt.ErrorMatches(err, `missing value for resource attribute: "invalid"`))
+	qt.Assert(t, qt.HasLen(attrs, 2))
+	qt.Check(t, qt.Equals(attrs[0], attribute.String("team", "core")))
+	qt.Check(t, qt.Equals(attrs[1], attribute.String("region", "eu,west")))
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"sync/atomic"
+	"time"
+
+	"azugo.io/azugo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.uber.org/zap"
+)
+
+const unmatchedRouteWarnInterval = time.Minute
+
+// unmatchedRouteReporter counts requests that do not match any route and
+// logs rate-limited warning about them.
+type unmatchedRouteReporter struct {
+	counter     metric.Int64Counter
+	cardinality *cardinalityGuard
+	lastWarn    atomic.Int64
+}
+
+func newUnmatchedRouteReporter(meter metric.Meter, cardinality *cardinalityGuard) (*unmatchedRouteReporter, error) {
+	counter, err := meter.Int64Counter(
+		"azugo.http.server.unmatched_routes",
+		metric.WithDescription("Number of requests that did not match any registered route."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unmatchedRouteReporter{
+		counter:     counter,
+		cardinality: cardinality,
+	}, nil
+}
+
+func (r *unmatchedRouteReporter) report(ctx *azugo.Context) {
+	attrs := r.cardinality.filter(ctx, []attribute.KeyValue{semconv.HTTPRequestMethodKey.String(ctx.Method())})
+
+	r.counter.Add(ctx, 1, metric.WithAttributes(attrs...))
+
+	now := time.Now().UnixNano()
+
+	last := r.lastWarn.Load()
+	if now-last < int64(unmatchedRouteWarnInterval) || !r.lastWarn.CompareAndSwap(last, now) {
+		return
+	}
+
+	ctx.Log().Warn("Request does not match any registered route",
+		zap.String("method", ctx.Method()),
+		zap.String("path", ctx.Path()),
+	)
+}
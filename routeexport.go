@@ -0,0 +1,139 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+type routeExport struct {
+	routes    []string
+	processor trace.SpanProcessor
+}
+
+// routeSpanProcessor passes spans of traces with local root span route matching
+// the configured routes to the route span processor instead of the next one.
+//
+// Route is resolved from the "http.route" attribute of the local root span when
+// it is started, so spans ending after the local root span are passed to the
+// next span processor.
+type routeSpanProcessor struct {
+	routes []routeExport
+	next   trace.SpanProcessor
+
+	mu     sync.RWMutex
+	traces map[oteltrace.TraceID]trace.SpanProcessor
+}
+
+func newRouteSpanProcessor(routes []routeExport, next trace.SpanProcessor) *routeSpanProcessor {
+	return &routeSpanProcessor{
+		routes: routes,
+		next:   next,
+		traces: make(map[oteltrace.TraceID]trace.SpanProcessor),
+	}
+}
+
+func (p *routeSpanProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	traceID := s.SpanContext().TraceID()
+
+	if ps := s.Parent(); ps.IsValid() && !ps.IsRemote() {
+		p.processor(traceID).OnStart(parent, s)
+
+		return
+	}
+
+	processor := p.next
+
+	for _, kv := range s.Attributes() {
+		if kv.Key != semconv.HTTPRouteKey {
+			continue
+		}
+
+		if rp := p.match(kv.Value.AsString()); rp != nil {
+			processor = rp
+
+			p.mu.Lock()
+			p.traces[traceID] = rp
+			p.mu.Unlock()
+		}
+
+		break
+	}
+
+	processor.OnStart(parent, s)
+}
+
+func (p *routeSpanProcessor) OnEnd(s trace.ReadOnlySpan) {
+	traceID := s.SpanContext().TraceID()
+
+	processor := p.processor(traceID)
+
+	if ps := s.Parent(); !ps.IsValid() || ps.IsRemote() {
+		p.mu.Lock()
+		delete(p.traces, traceID)
+		p.mu.Unlock()
+	}
+
+	processor.OnEnd(s)
+}
+
+func (p *routeSpanProcessor) processor(traceID oteltrace.TraceID) trace.SpanProcessor {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if processor, ok := p.traces[traceID]; ok {
+		return processor
+	}
+
+	return p.next
+}
+
+func (p *routeSpanProcessor) match(route string) trace.SpanProcessor {
+	for _, r := range p.routes {
+		for _, pattern := range r.routes {
+			if pattern == route {
+				return r.processor
+			}
+
+			// Trailing wildcard matches all routes with the prefix.
+			if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(route, prefix+"/") {
+				return r.processor
+			}
+
+			if ok, _ := path.Match(pattern, route); ok {
+				return r.processor
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *routeSpanProcessor) Shutdown(ctx context.Context) error {
+	err := p.next.Shutdown(ctx)
+
+	for _, r := range p.routes {
+		err = errors.Join(err, r.processor.Shutdown(ctx))
+	}
+
+	return err
+}
+
+func (p *routeSpanProcessor) ForceFlush(ctx context.Context) error {
+	err := p.next.ForceFlush(ctx)
+
+	for _, r := range p.routes {
+		err = errors.Join(err, r.processor.ForceFlush(ctx))
+	}
+
+	return err
+}
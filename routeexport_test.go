@@ -0,0 +1,42 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRouteSpanProcessor(t *testing.T) {
+	partner := tracetest.NewSpanRecorder()
+	primary := tracetest.NewSpanRecorder()
+
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(newRouteSpanProcessor([]routeExport{
+		{routes: []string{"/partner-api/*"}, processor: partner},
+	}, primary)))
+
+	tracer := tp.Tracer("test")
+
+	for _, route := range []string{"/partner-api/v1/orders/{id}", "/api/orders"} {
+		ctx, root := tracer.Start(context.Background(), "GET "+route,
+			oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+			oteltrace.WithAttributes(semconv.HTTPRoute(route)),
+		)
+
+		_, child := tracer.Start(ctx, "child")
+		child.End()
+		root.End()
+	}
+
+	qt.Assert(t, qt.HasLen(partner.Ended(), 2))
+	qt.Check(t, qt.Equals(partner.Ended()[1].Name(), "GET /partner-api/v1/orders/{id}"))
+	qt.Assert(t, qt.HasLen(primary.Ended(), 2))
+	qt.Check(t, qt.Equals(primary.Ended()[1].Name(), "GET /api/orders"))
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"sync"
+
+	"azugo.io/azugo"
+	"azugo.io/core"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+type routeSettings struct {
+	skip    bool
+	sampler trace.Sampler
+}
+
+// RouteOption configures telemetry of the route.
+type RouteOption func(s *routeSettings)
+
+// RouteSkip excludes requests of the route from tracing.
+func RouteSkip() RouteOption {
+	return func(s *routeSettings) {
+		s.skip = true
+	}
+}
+
+// RouteSampleRate sets ratio of the route traces to sample. It takes
+// precedence over route_sampling configuration and has effect only if route
+// sampling is enabled by route_sampling configuration, as otherwise the SDK
+// default sampler is used.
+func RouteSampleRate(rate float64) RouteOption {
+	return func(s *routeSettings) {
+		s.sampler = trace.TraceIDRatioBased(rate)
+	}
+}
+
+// routeRegistry holds telemetry settings declared next to the route registration.
+type routeRegistry struct {
+	mu     sync.RWMutex
+	routes map[string]routeSettings
+}
+
+func newRouteRegistry() *routeRegistry {
+	return &routeRegistry{
+		routes: make(map[string]routeSettings),
+	}
+}
+
+func (r *routeRegistry) set(route string, opts ...RouteOption) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.routes[route]
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	r.routes[route] = s
+}
+
+func (r *routeRegistry) get(route string) (routeSettings, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.routes[route]
+
+	return s, ok
+}
+
+// filter rejects requests of the skipped routes.
+func (r *routeRegistry) filter(ctx *azugo.Context) bool {
+	s, ok := r.get(ctx.RouterPath())
+
+	return !ok || !s.skip
+}
+
+// sampler returns sampler declared for the route if any.
+func (r *routeRegistry) sampler(route string) trace.Sampler {
+	s, ok := r.get(route)
+	if !ok {
+		return nil
+	}
+
+	return s.sampler
+}
+
+// Route configures telemetry of the route template next to its registration:
+//
+//	app.Get("/healthz", healthz)
+//	opentelemetry.Route(t, "/healthz", opentelemetry.RouteSkip())
+//
+// It returns false if OpenTelemetry is disabled and settings have not been applied.
+func Route(t core.Tasker, route string, opts ...RouteOption) bool {
+	s, ok := t.(*setup)
+	if !ok || s.routes == nil {
+		return false
+	}
+
+	s.routes.set(route, opts...)
+
+	return true
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"path"
+	"sort"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+type routeRate struct {
+	pattern string
+	sampler trace.Sampler
+}
+
+// routeSampler samples spans using sampling rate configured for the route
+// in the "http.route" server span start attribute.
+type routeSampler struct {
+	exact     map[string]trace.Sampler
+	patterns  []routeRate
+	overrides *routeRegistry
+	next      trace.Sampler
+}
+
+// newRouteSampler returns sampler that uses next sampler for spans without
+// matching route.
+func newRouteSampler(rates map[string]float64, next trace.Sampler) *routeSampler {
+	s := &routeSampler{
+		exact: make(map[string]trace.Sampler, len(rates)),
+		next:  next,
+	}
+
+	for route, rate := range rates {
+		s.exact[route] = trace.TraceIDRatioBased(rate)
+		s.patterns = append(s.patterns, routeRate{
+			pattern: route,
+			sampler: s.exact[route],
+		})
+	}
+
+	// Longer, more specific patterns are matched first.
+	sort.Slice(s.patterns, func(i, j int) bool {
+		if len(s.patterns[i].pattern) != len(s.patterns[j].pattern) {
+			return len(s.patterns[i].pattern) > len(s.patterns[j].pattern)
+		}
+
+		return s.patterns[i].pattern < s.patterns[j].pattern
+	})
+
+	return s
+}
+
+func (s *routeSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if p.Kind == oteltrace.SpanKindServer {
+		for _, kv := range p.Attributes {
+			if kv.Key != semconv.HTTPRouteKey {
+				continue
+			}
+
+			if sampler := s.match(kv.Value.AsString()); sampler != nil {
+				return sampler.ShouldSample(p)
+			}
+
+			break
+		}
+	}
+
+	return s.next.ShouldSample(p)
+}
+
+func (s *routeSampler) match(route string) trace.Sampler {
+	if s.overrides != nil {
+		if sampler := s.overrides.sampler(route); sampler != nil {
+			return sampler
+		}
+	}
+
+	if sampler, ok := s.exact[route]; ok {
+		return sampler
+	}
+
+	for _, r := range s.patterns {
+		if ok, _ := path.Match(r.pattern, route); ok {
+			return r.sampler
+		}
+	}
+
+	return nil
+}
+
+func (s *routeSampler) Description() string {
+	return "RouteSampler"
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRouteSampler(t *testing.T) {
+	sampler := newRouteSampler(map[string]float64{
+		"/healthz":           0,
+		"/api/*":             0,
+		"/api/orders/{id}/*": 1,
+	}, trace.AlwaysSample())
+
+	tests := []struct {
+		route    string
+		expected trace.SamplingDecision
+	}{
+		{route: "/healthz", expected: trace.Drop},
+		{route: "/api/orders", expected: trace.Drop},
+		{route: "/api/orders/{id}/items", expected: trace.RecordAndSample},
+		{route: "/users", expected: trace.RecordAndSample},
+	}
+
+	for _, test := range tests {
+		t.Run(test.route, func(t *testing.T) {
+			res := sampler.ShouldSample(trace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       oteltrace.TraceID{1},
+				Kind:          oteltrace.SpanKindServer,
+				Attributes:    []attribute.KeyValue{semconv.HTTPRoute(test.route)},
+			})
+			qt.Check(t, qt.Equals(res.Decision, test.expected))
+		})
+	}
+}
+
+func TestRouteSamplerOverrides(t *testing.T) {
+	routes := newRouteRegistry()
+	routes.set("/api/payments", RouteSampleRate(1))
+	routes.set("/healthz", RouteSkip())
+
+	sampler := newRouteSampler(map[string]float64{
+		"/api/*": 0,
+	}, trace.AlwaysSample())
+	sampler.overrides = routes
+
+	sample := func(route string) trace.SamplingDecision {
+		return sampler.ShouldSample(trace.SamplingParameters{
+			ParentContext: context.Background(),
+			TraceID:       oteltrace.TraceID{1},
+			Kind:          oteltrace.SpanKindServer,
+			Attributes:    []attribute.KeyValue{semconv.HTTPRoute(route)},
+		}).Decision
+	}
+
+	qt.Check(t, qt.Equals(sample("/api/payments"), trace.RecordAndSample))
+	qt.Check(t, qt.Equals(sample("/api/orders"), trace.Drop))
+	qt.Check(t, qt.Equals(sample("/healthz"), trace.RecordAndSample))
+}
+
+func TestNewSamplerRoutes(t *testing.T) {
+	routes := newRouteRegistry()
+	routes.set("/api/orders", RouteSampleRate(1))
+
+	qt.Check(t, qt.IsNil(newSampler(&Configuration{}, routes)))
+
+	sampler := newSampler(&Configuration{
+		RouteSampling: map[string]float64{"/api/*": 0},
+	}, routes)
+	qt.Assert(t, qt.IsNotNil(sampler))
+
+	sample := func(route string) trace.SamplingDecision {
+		return sampler.ShouldSample(trace.SamplingParameters{
+			ParentContext: context.Background(),
+			TraceID:       oteltrace.TraceID{1},
+			Kind:          oteltrace.SpanKindServer,
+			Attributes:    []attribute.KeyValue{semconv.HTTPRoute(route)},
+		}).Decision
+	}
+
+	qt.Check(t, qt.Equals(sample("/api/orders"), trace.RecordAndSample))
+	qt.Check(t, qt.Equals(sample("/api/users"), trace.Drop))
+	qt.Check(t, qt.Equals(sample("/healthz"), trace.RecordAndSample))
+}
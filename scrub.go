@@ -0,0 +1,267 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// ScrubActionRemove removes matching attribute.
+	ScrubActionRemove = "remove"
+	// ScrubActionHash replaces matching attribute value with truncated HMAC-SHA256
+	// of it keyed by redact_hash_key configuration value. If the key is not set,
+	// random key is generated for each process so hashes are not comparable
+	// across processes.
+	ScrubActionHash = "hash"
+	// ScrubActionMask replaces matching part of the attribute value with a fixed mask.
+	ScrubActionMask = "mask"
+)
+
+type scrubRule struct {
+	key     *regexp.Regexp
+	value   *regexp.Regexp
+	action  string
+	hashKey []byte
+}
+
+// attributeScrubber removes or hashes attributes matching configured rules.
+type attributeScrubber struct {
+	rules []scrubRule
+}
+
+func newAttributeScrubber(rules []ScrubRule, hashKey []byte) (*attributeScrubber, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	s := &attributeScrubber{
+		rules: make([]scrubRule, 0, len(rules)),
+	}
+
+	for i, r := range rules {
+		rule := scrubRule{action: r.Action, hashKey: hashKey}
+		if rule.action == "" {
+			rule.action = ScrubActionRemove
+		}
+
+		var err error
+
+		if r.Key != "" {
+			if rule.key, err = regexp.Compile(r.Key); err != nil {
+				return nil, fmt.Errorf("invalid scrub rule %d key pattern: %w", i, err)
+			}
+		}
+
+		if r.Value != "" {
+			if rule.value, err = regexp.Compile(r.Value); err != nil {
+				return nil, fmt.Errorf("invalid scrub rule %d value pattern: %w", i, err)
+			}
+		}
+
+		s.rules = append(s.rules, rule)
+	}
+
+	return s, nil
+}
+
+// scrub returns attributes with rules applied. Provided slice is never modified.
+func (s *attributeScrubber) scrub(attrs []attribute.KeyValue) []attribute.KeyValue {
+	var scrubbed []attribute.KeyValue
+
+	for i, kv := range attrs {
+		res, keep, changed := s.apply(kv)
+		if !changed && scrubbed == nil {
+			continue
+		}
+
+		if scrubbed == nil {
+			scrubbed = make([]attribute.KeyValue, 0, len(attrs))
+			scrubbed = append(scrubbed, attrs[:i]...)
+		}
+
+		if keep {
+			scrubbed = append(scrubbed, res)
+		}
+	}
+
+	if scrubbed == nil {
+		return attrs
+	}
+
+	return scrubbed
+}
+
+func (s *attributeScrubber) apply(kv attribute.KeyValue) (attribute.KeyValue, bool, bool) {
+	changed := false
+
+	for _, r := range s.rules {
+		if r.key != nil && !r.key.MatchString(string(kv.Key)) {
+			continue
+		}
+
+		switch kv.Value.Type() {
+		case attribute.STRING:
+			v := kv.Value.AsString()
+			if r.value != nil && !r.value.MatchString(v) {
+				continue
+			}
+
+			if r.action == ScrubActionRemove {
+				return kv, false, true
+			}
+
+			kv = kv.Key.String(r.scrubValue(v))
+		case attribute.STRINGSLICE:
+			vals := kv.Value.AsStringSlice()
+
+			matched := false
+
+			for j, v := range vals {
+				if r.value != nil && !r.value.MatchString(v) {
+					continue
+				}
+
+				matched = true
+				vals[j] = r.scrubValue(v)
+			}
+
+			if !matched {
+				continue
+			}
+
+			if r.action == ScrubActionRemove {
+				return kv, false, true
+			}
+
+			kv = kv.Key.StringSlice(vals)
+		default:
+			// Value patterns apply only to string values.
+			if r.value != nil {
+				continue
+			}
+
+			if r.action == ScrubActionRemove {
+				return kv, false, true
+			}
+
+			kv = kv.Key.String(r.scrubValue(kv.Value.Emit()))
+		}
+
+		changed = true
+	}
+
+	return kv, true, changed
+}
+
+func (r *scrubRule) scrubValue(v string) string {
+	switch r.action {
+	case ScrubActionHash:
+		return redactClaim(r.hashKey, ClaimRedactHash, v)
+	case ScrubActionMask:
+		if r.value != nil {
+			return r.value.ReplaceAllLiteralString(v, redactedClaimValue)
+		}
+
+		return redactedClaimValue
+	default:
+		return v
+	}
+}
+
+// scrubSpanProcessor passes spans with scrubbed attributes to the wrapped
+// span processor.
+type scrubSpanProcessor struct {
+	trace.SpanProcessor
+
+	scrubber *attributeScrubber
+}
+
+func newScrubSpanProcessor(p trace.SpanProcessor, scrubber *attributeScrubber) trace.SpanProcessor {
+	if scrubber == nil {
+		return p
+	}
+
+	return &scrubSpanProcessor{
+		SpanProcessor: p,
+		scrubber:      scrubber,
+	}
+}
+
+func (p *scrubSpanProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.SpanProcessor.OnEnd(&scrubbedSpan{
+		ReadOnlySpan: s,
+		scrubber:     p.scrubber,
+	})
+}
+
+// statusDescriptionKey is the attribute key scrub rules are matched against
+// for the span status description.
+const statusDescriptionKey = attribute.Key("otel.status_description")
+
+// scrubbedSpan is a read-only span view with scrubbed span, event and link
+// attributes and status description.
+type scrubbedSpan struct {
+	trace.ReadOnlySpan
+
+	scrubber *attributeScrubber
+}
+
+func (s *scrubbedSpan) Attributes() []attribute.KeyValue {
+	return s.scrubber.scrub(s.ReadOnlySpan.Attributes())
+}
+
+func (s *scrubbedSpan) Events() []trace.Event {
+	events := s.ReadOnlySpan.Events()
+	if len(events) == 0 {
+		return events
+	}
+
+	scrubbed := make([]trace.Event, len(events))
+	for i, e := range events {
+		e.Attributes = s.scrubber.scrub(e.Attributes)
+		scrubbed[i] = e
+	}
+
+	return scrubbed
+}
+
+func (s *scrubbedSpan) Links() []trace.Link {
+	links := s.ReadOnlySpan.Links()
+	if len(links) == 0 {
+		return links
+	}
+
+	scrubbed := make([]trace.Link, len(links))
+	for i, l := range links {
+		l.Attributes = s.scrubber.scrub(l.Attributes)
+		scrubbed[i] = l
+	}
+
+	return scrubbed
+}
+
+func (s *scrubbedSpan) Status() trace.Status {
+	status := s.ReadOnlySpan.Status()
+	if status.Code != codes.Error || status.Description == "" {
+		return status
+	}
+
+	kv, keep, changed := s.scrubber.apply(statusDescriptionKey.String(status.Description))
+	if !changed {
+		return status
+	}
+
+	status.Description = ""
+	if keep {
+		status.Description = kv.Value.Emit()
+	}
+
+	return status
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
+func TestAttributeScrubber(t *testing.T) {
+	s, err := newAttributeScrubber([]ScrubRule{
+		{Key: `^url\.full$`, Value: `[^/?&=]+@[^/?&=]+`, Action: ScrubActionMask},
+		{Key: `^user\.email$`, Action: ScrubActionHash},
+		{Key: `^http\.request\.header\.authorization$`},
+	}, []byte("secret"))
+	qt.Assert(t, qt.IsNil(err))
+
+	in := []attribute.KeyValue{
+		semconv.URLFull("https://example.com/users/john@example.com?x=1"),
+		semconv.HTTPRequestMethodGet,
+		attribute.StringSlice("http.request.header.authorization", []string{"Bearer secret"}),
+		attribute.String("user.email", "john@example.com"),
+	}
+
+	out := s.scrub(in)
+	qt.Assert(t, qt.HasLen(out, 3))
+	qt.Check(t, qt.Equals(out[0], semconv.URLFull("https://example.com/users/****?x=1")))
+	qt.Check(t, qt.Equals(out[1], semconv.HTTPRequestMethodGet))
+	qt.Check(t, qt.Equals(out[2], attribute.String("user.email", redactClaim([]byte("secret"), ClaimRedactHash, "john@example.com"))))
+	qt.Check(t, qt.Equals(in[0], semconv.URLFull("https://example.com/users/john@example.com?x=1")))
+
+	_, err = newAttributeScrubber([]ScrubRule{{Key: `(`}}, nil)
+	qt.Check(t, qt.ErrorMatches(err, `invalid scrub rule 0 key pattern: .*`))
+}
+
+func TestScrubbedSpan(t *testing.T) {
+	s, err := newAttributeScrubber([]ScrubRule{
+		{Value: `[^\s@]+@[^\s@]+`, Action: ScrubActionMask},
+	}, []byte("secret"))
+	qt.Assert(t, qt.IsNil(err))
+
+	span := &scrubbedSpan{
+		ReadOnlySpan: tracetest.SpanStub{
+			Status: sdktrace.Status{Code: codes.Error, Description: "user john@example.com not found"},
+			Links: []sdktrace.Link{
+				{Attributes: []attribute.KeyValue{attribute.String("user.email", "john@example.com")}},
+			},
+		}.Snapshot(),
+		scrubber: s,
+	}
+
+	qt.Check(t, qt.Equals(span.Status().Description, "user **** not found"))
+	qt.Check(t, qt.Equals(span.Links()[0].Attributes[0], attribute.String("user.email", "****")))
+}
@@ -6,11 +6,13 @@ package opentelemetry
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"runtime"
-	"strings"
+	"time"
 
 	"azugo.io/azugo"
 	"azugo.io/core/cache"
@@ -20,6 +22,9 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
@@ -30,6 +35,12 @@ import (
 type setup struct {
 	app         *azugo.App
 	config      *Configuration
+	instr       *instrumentation
+	res         *resource.Resource
+	stats       *exportStats
+	routes      *routeRegistry
+	filters     int
+	flushFns    []func(context.Context) error
 	shutdownFns []func(context.Context) error
 }
 
@@ -42,24 +53,51 @@ func (setup) Start(context.Context) error {
 }
 
 func (s *setup) Stop() {
-	ctx := s.app.BackgroundContext()
+	timeout := s.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	// Application background context might already be canceled during shutdown.
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
 	var err error
 
+	// Export pending telemetry before shutting down providers.
+	for _, fn := range s.flushFns {
+		err = errors.Join(err, fn(ctx))
+	}
+
 	for _, fn := range s.shutdownFns {
 		err = errors.Join(err, fn(ctx))
 	}
 
+	s.flushFns = nil
 	s.shutdownFns = nil
 
-	s.app.Log().Warn("Open Telemetry shutdown error", zap.Error(err))
+	if err != nil {
+		s.app.Log().Warn("Open Telemetry shutdown error", zap.Error(err))
+	}
 }
 
-func newPropagator() propagation.TextMapPropagator {
-	return propagation.NewCompositeTextMapPropagator(
+func newPropagator(config *Configuration, res *resource.Resource) propagation.TextMapPropagator {
+	propagators := []propagation.TextMapPropagator{
 		propagation.TraceContext{},
 		propagation.Baggage{},
-	)
+	}
+
+	if config.IDGenerator == IDGeneratorXRay {
+		propagators = append(propagators, xrayPropagator{})
+	}
+
+	propagator := propagation.NewCompositeTextMapPropagator(propagators...)
+
+	if len(config.BaggageResourceAttributes) > 0 && res != nil {
+		return newResourceBaggagePropagator(res, config.BaggageResourceAttributes, propagator)
+	}
+
+	return propagator
 }
 
 func sysinfoAttrs() ([]attribute.KeyValue, string) {
@@ -139,7 +177,7 @@ func sysinfoAttrs() ([]attribute.KeyValue, string) {
 	return attrs, instanceID
 }
 
-func newTraceProvider(app *azugo.App, config *Configuration) (*trace.TracerProvider, error) {
+func newOTLPTraceExporter(app *azugo.App, config *Configuration) (trace.SpanExporter, error) {
 	opt := make([]otlptracehttp.Option, 0, 1)
 
 	if config.Endpoint != "" {
@@ -158,16 +196,39 @@ func newTraceProvider(app *azugo.App, config *Configuration) (*trace.TracerProvi
 		}
 	}
 
-	if config.ElasticAPMSecretToken != "" {
-		opt = append(opt, otlptracehttp.WithHeaders(map[string]string{
-			"Authorization": "ApiKey " + config.ElasticAPMSecretToken,
-		}))
+	auth := elasticAPMAuthorization(config.ElasticAPMSecretToken, config.ElasticAPMAPIKey)
+
+	if len(config.Headers) > 0 || auth != "" {
+		headers := make(map[string]string, len(config.Headers)+1)
+		for k, v := range config.Headers {
+			headers[k] = v
+		}
+
+		if auth != "" {
+			headers["Authorization"] = auth
+		}
+
+		opt = append(opt, otlptracehttp.WithHeaders(headers))
 	}
 
-	opt = append(opt, otlptracehttp.WithTLSClientConfig(&tls.Config{
-		//nolint:gosec
-		InsecureSkipVerify: config.InsecureSkipVerify,
-	}))
+	if config.Compression == CompressionGzip {
+		opt = append(opt, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+
+	if config.Timeout > 0 {
+		opt = append(opt, otlptracehttp.WithTimeout(config.Timeout))
+	}
+
+	if r := config.Retry; r.Disabled || r.InitialInterval > 0 || r.MaxInterval > 0 || r.MaxElapsedTime > 0 {
+		opt = append(opt, otlptracehttp.WithRetry(retryConfig(&r)))
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	opt = append(opt, otlptracehttp.WithTLSClientConfig(tlsConfig))
 
 	// TODO: support for GRPC
 	exporter, err := otlptrace.New(app.BackgroundContext(), otlptracehttp.NewClient(opt...))
@@ -175,76 +236,396 @@ func newTraceProvider(app *azugo.App, config *Configuration) (*trace.TracerProvi
 		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
 	}
 
-	attrs := make([]attribute.KeyValue, 0, 4)
+	return exporter, nil
+}
+
+// endpointConfig returns exporter configuration for the endpoint with other
+// settings shared with the primary endpoint.
+func endpointConfig(config *Configuration, e *ExporterEndpoint) *Configuration {
+	ec := *config
+	ec.Endpoint = e.Endpoint
+	ec.Headers = e.Headers
+	ec.InsecureSkipVerify = e.InsecureSkipVerify
+	ec.ElasticAPMSecretToken = e.ElasticAPMSecretToken
+	ec.ElasticAPMAPIKey = e.ElasticAPMAPIKey
+
+	return &ec
+}
 
-	serviceName := config.ServiceName
-	if serviceName == "" {
-		serviceName = app.AppName
+// elasticAPMAuthorization returns Authorization header value for the Elastic APM
+// server. API key takes precedence over the secret token if both are set.
+func elasticAPMAuthorization(secretToken, apiKey string) string {
+	if apiKey != "" {
+		return "ApiKey " + apiKey
 	}
 
-	attrs = append(attrs,
-		semconv.ServiceName(serviceName),
-		semconv.ServiceVersion(app.AppVer),
-		semconv.DeploymentEnvironmentName(strings.ToLower(string(app.Env()))),
-	)
+	if secretToken != "" {
+		return "Bearer " + secretToken
+	}
+
+	return ""
+}
+
+// retryConfig returns exporter retry configuration with configured values
+// overriding exporter defaults.
+func retryConfig(config *Retry) otlptracehttp.RetryConfig {
+	retry := otlptracehttp.RetryConfig{
+		Enabled:         !config.Disabled,
+		InitialInterval: 5 * time.Second,
+		MaxInterval:     30 * time.Second,
+		MaxElapsedTime:  time.Minute,
+	}
+
+	if config.InitialInterval > 0 {
+		retry.InitialInterval = config.InitialInterval
+	}
+
+	if config.MaxInterval > 0 {
+		retry.MaxInterval = config.MaxInterval
+	}
+
+	if config.MaxElapsedTime > 0 {
+		retry.MaxElapsedTime = config.MaxElapsedTime
+	}
+
+	return retry
+}
+
+// newTLSConfig returns TLS configuration for the exporters with custom CA,
+// client certificate for mutual TLS and server name override if configured.
+func newTLSConfig(config *Configuration) (*tls.Config, error) {
+	c := &config.TLS
+
+	tlsConfig := &tls.Config{
+		//nolint:gosec
+		InsecureSkipVerify: config.InsecureSkipVerify || c.InsecureSkipVerify,
+		ServerName:         c.ServerNameOverride,
+	}
+
+	switch c.MinVersion {
+	case "1.0":
+		tlsConfig.MinVersion = tls.VersionTLS10
+	case "1.1":
+		tlsConfig.MinVersion = tls.VersionTLS11
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading OTLP CA certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no valid certificates found in OTLP CA certificate file: %s", c.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading OTLP client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func newTraceExporter(app *azugo.App, config *Configuration) (trace.SpanExporter, error) {
+	switch config.Exporter {
+	case ExporterConsole:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("creating console trace exporter: %w", err)
+		}
+
+		return exporter, nil
+	default:
+		return newOTLPTraceExporter(app, config)
+	}
+}
+
+func newTraceProvider(app *azugo.App, config *Configuration, cfg *otelcfg, res *resource.Resource, stats *exportStats, routes *routeRegistry) (*trace.TracerProvider, error) {
+	scrubber, err := newAttributeScrubber(config.ScrubAttributes, redactHashKey(config))
+	if err != nil {
+		return nil, err
+	}
+
+	exporter := cfg.traceExporter
+	if exporter == nil {
+		exporter, err = newTraceExporter(app, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	processor := stats.batchSpanProcessor(exporter)
+	if config.Exporter == ExporterConsole && cfg.traceExporter == nil {
+		// Write spans immediately so they are visible right away during development.
+		processor = trace.NewSimpleSpanProcessor(exporter)
+	}
+
+	if len(config.RouteExporters) > 0 {
+		routes := make([]routeExport, 0, len(config.RouteExporters))
+
+		for i, r := range config.RouteExporters {
+			rexporter, err := newOTLPTraceExporter(app, endpointConfig(config, &r.ExporterEndpoint))
+			if err != nil {
+				return nil, fmt.Errorf("route exporter %d: %w", i, err)
+			}
 
-	// Add system information attributes.
-	sysattrs, instanceID := sysinfoAttrs()
+			routes = append(routes, routeExport{
+				routes:    r.Routes,
+				processor: stats.batchSpanProcessor(rexporter),
+			})
+		}
+
+		processor = newRouteSpanProcessor(routes, processor)
+	}
+
+	processors := make([]trace.SpanProcessor, 0, 2+len(config.AdditionalEndpoints)+len(cfg.spanProcessors))
+
+	if config.Migration.Endpoint != "" {
+		mc := *config
+		mc.Endpoint = config.Migration.Endpoint
+		mc.InsecureSkipVerify = config.Migration.InsecureSkipVerify
+		mc.ElasticAPMSecretToken = config.Migration.ElasticAPMSecretToken
+		mc.ElasticAPMAPIKey = config.Migration.ElasticAPMAPIKey
+		// Primary endpoint headers may contain credentials.
+		mc.Headers = nil
+
+		mexporter, err := newOTLPTraceExporter(app, &mc)
+		if err != nil {
+			return nil, fmt.Errorf("migration: %w", err)
+		}
+
+		processors = append(processors,
+			newRatioSpanProcessor(processor, ratioOrAll(config.Migration.PrimaryRatio)),
+			newRatioSpanProcessor(stats.batchSpanProcessor(mexporter), ratioOrAll(config.Migration.Ratio)),
+		)
+	} else {
+		processors = append(processors, processor)
+	}
+
+	for i, e := range config.AdditionalEndpoints {
+		eexporter, err := newOTLPTraceExporter(app, endpointConfig(config, &e))
+		if err != nil {
+			return nil, fmt.Errorf("additional endpoint %d: %w", i, err)
+		}
 
-	if instanceID != "" {
-		attrs = append(attrs, semconv.ServiceInstanceID(instanceID))
+		processors = append(processors, stats.batchSpanProcessor(eexporter))
 	}
 
-	attrs = append(attrs, sysattrs...)
+	processors = append(processors, cfg.spanProcessors...)
+
+	offset := clockOffset(cfg, config)
 
-	traceProvider := trace.NewTracerProvider(
-		trace.WithBatcher(
-			exporter,
-		),
+	for i, p := range processors {
+		processors[i] = newClockSkewSpanProcessor(newScrubSpanProcessor(p, scrubber), offset)
+	}
+
+	if config.NPlusOne.Enabled {
+		processors = []trace.SpanProcessor{newNPlusOneProcessor(&config.NPlusOne, processors...)}
+	}
+
+	if config.TailSampling.Enabled {
+		processors = []trace.SpanProcessor{newTailSamplingProcessor(&config.TailSampling, processors...)}
+	}
+
+	opts := make([]trace.TracerProviderOption, 0, len(processors)+4)
+
+	for _, p := range processors {
+		opts = append(opts, trace.WithSpanProcessor(p))
+	}
 
-		trace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			attrs...,
-		)),
+	opts = append(opts,
+		trace.WithResource(res),
+
+		trace.WithSpanLimits(spanLimits(&config.SpanLimits)),
 	)
 
+	if config.IDGenerator == IDGeneratorXRay {
+		opts = append(opts, trace.WithIDGenerator(xrayIDGenerator{}))
+	}
+
+	if sampler := newSampler(config, routes); sampler != nil {
+		opts = append(opts, trace.WithSampler(sampler))
+	}
+
+	traceProvider := trace.NewTracerProvider(opts...)
+
 	return traceProvider, nil
 }
 
-func traceConfig(opts ...Option) *otelcfg {
+// newSampler returns sampler based on the configuration or nil if SDK default
+// sampler configured with OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG
+// environment variables should be used.
+//
+// Tenant sampling rates take precedence over route sampling rates. Sampling
+// rates declared for the routes take precedence over configured ones. Spans
+// without matching rate are sampled the same way as by SDK default sampler
+// based on the parent span sampling decision.
+func newSampler(config *Configuration, routes *routeRegistry) trace.Sampler {
+	tailSampling := config.TailSampling.Enabled
+	routeSampling := len(config.RouteSampling) > 0
+	tenantSampling := len(config.TenantSampling.Rates) > 0 || config.TenantSampling.DefaultRate > 0
+
+	if !tailSampling && !routeSampling && !tenantSampling {
+		return nil
+	}
+
+	root := trace.AlwaysSample()
+
+	if tailSampling {
+		root = trace.TraceIDRatioBased(config.TailSampling.Ratio)
+	}
+
+	if routeSampling {
+		rs := newRouteSampler(config.RouteSampling, root)
+		rs.overrides = routes
+		root = rs
+	}
+
+	if tenantSampling {
+		root = newTenantSampler(&config.TenantSampling, root)
+	}
+
+	sampler := trace.ParentBased(root)
+
+	if tailSampling {
+		sampler = &tailSampler{base: sampler}
+	}
+
+	return sampler
+}
+
+// spanLimits returns span limits with configured values overriding SDK defaults.
+func spanLimits(config *SpanLimits) trace.SpanLimits {
+	limits := trace.NewSpanLimits()
+
+	if config.AttributeValueLengthLimit != 0 {
+		limits.AttributeValueLengthLimit = config.AttributeValueLengthLimit
+	}
+
+	if config.AttributeCountLimit != 0 {
+		limits.AttributeCountLimit = config.AttributeCountLimit
+	}
+
+	if config.EventCountLimit != 0 {
+		limits.EventCountLimit = config.EventCountLimit
+	}
+
+	if config.LinkCountLimit != 0 {
+		limits.LinkCountLimit = config.LinkCountLimit
+	}
+
+	if config.AttributePerEventCountLimit != 0 {
+		limits.AttributePerEventCountLimit = config.AttributePerEventCountLimit
+	}
+
+	if config.AttributePerLinkCountLimit != 0 {
+		limits.AttributePerLinkCountLimit = config.AttributePerLinkCountLimit
+	}
+
+	return limits
+}
+
+// newConfig applies options without setting any defaults.
+func newConfig(opts ...Option) *otelcfg {
 	cfg := otelcfg{}
 	for _, opt := range opts {
 		opt.apply(&cfg)
 	}
 
+	return &cfg
+}
+
+func traceConfig(opts ...Option) *otelcfg {
+	cfg := newConfig(opts...)
+
 	if cfg.TracerProvider == nil {
 		cfg.TracerProvider = otel.GetTracerProvider()
 	}
 
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+
 	if cfg.Propagators == nil {
 		cfg.Propagators = otel.GetTextMapPropagator()
 	}
 
 	if cfg.routeSpanNameFormatter == nil {
 		cfg.routeSpanNameFormatter = defaultRouteSpanNameFunc
+
+		if cfg.routeSpanNameBasePath || cfg.routeSpanNameCollapse {
+			cfg.routeSpanNameFormatter = newRouteSpanNameFunc(cfg.routeSpanNameBasePath, cfg.routeSpanNameCollapse)
+		}
+	}
+
+	if cfg.spanStatusFromResponse == nil {
+		cfg.spanStatusFromResponse = defaultSpanStatusFromResponse
 	}
 
 	if cfg.instrSpanNameFormatter == nil {
 		cfg.instrSpanNameFormatter = defaultInstrSpanNameFormatter
 	}
 
+	cfg.cardinality.init(cfg.MeterProvider)
+
+	cacheMeter := cfg.MeterProvider.Meter(
+		ScopeName+"/cache",
+		metric.WithInstrumentationVersion(Version()),
+		metric.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
+
 	cfg.instrRecorders = append(cfg.instrRecorders,
 		instrRecorder{
 			Name:     "http-client",
-			Recorder: httpClientRecorder,
+			Recorder: newHTTPClientRecorder(cfg),
 			Ops:      []string{http.InstrumentationRequest},
 		},
 		instrRecorder{
 			Name:     "cache",
-			Recorder: cacheRecorder,
+			Recorder: newCacheRecorder(cacheMeter, cfg.cacheEventThreshold),
 			Ops:      []string{cache.InstrumentationGet, cache.InstrumentationSet, cache.InstrumentationDelete},
 		},
+		instrRecorder{
+			Name:     "messaging",
+			Recorder: messagingRecorder,
+			Ops:      []string{InstrumentationMessagePublish},
+		},
 	)
 
-	return &cfg
+	return cfg
+}
+
+// newDurationHistogram returns request duration histogram in seconds with the
+// configured bucket boundaries. No-op histogram is returned on error.
+func newDurationHistogram(meter metric.Meter, name, description string, buckets []float64) metric.Float64Histogram {
+	opts := []metric.Float64HistogramOption{
+		metric.WithDescription(description),
+		metric.WithUnit("s"),
+	}
+
+	if len(buckets) > 0 {
+		opts = append(opts, metric.WithExplicitBucketBoundaries(buckets...))
+	}
+
+	h, err := meter.Float64Histogram(name, opts...)
+	if err != nil {
+		otel.Handle(err)
+
+		return metricnoop.Float64Histogram{}
+	}
+
+	return h
 }
@@ -0,0 +1,91 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// SourceDefault means that the default value is used.
+	SourceDefault = "default"
+	// SourceFile means that the value is read from the configuration file.
+	SourceFile = "file"
+	// SourceEnv means that the value is read from the environment variable.
+	SourceEnv = "env"
+)
+
+// ValueSource describes where the effective configuration value came from.
+type ValueSource struct {
+	// Key of the configuration value.
+	Key string `json:"key"`
+	// Source of the value: "env", "file" or "default".
+	Source string `json:"source"`
+	// EnvVar is the name of environment variable the value is read from.
+	EnvVar string `json:"env_var,omitempty"`
+}
+
+// envBindings holds environment variable names bound to the configuration keys.
+type envBindings map[string][]string
+
+func (b envBindings) bind(v *viper.Viper, key string, envs ...string) {
+	if b != nil {
+		b[strings.ToLower(key)] = envs
+	}
+
+	_ = v.BindEnv(append([]string{key}, envs...)...)
+}
+
+// lookup returns the first set environment variable bound to the key.
+func (b envBindings) lookup(key string) (string, bool) {
+	for _, env := range b[key] {
+		if val, ok := os.LookupEnv(env); ok && val != "" {
+			return env, true
+		}
+	}
+
+	return "", false
+}
+
+// Sources returns sources of the configuration values under the prefix, sorted
+// by key. Values are resolved in the following order of precedence: environment
+// variables, configuration file and defaults. Only environment variables bound
+// by Bind of the configuration are reported, changes made to the configuration
+// in code after it has been loaded are not reflected.
+func (c *Configuration) Sources(prefix string, v *viper.Viper) []ValueSource {
+	prefix = strings.ToLower(prefix) + "."
+
+	keys := v.AllKeys()
+	sort.Strings(keys)
+
+	sources := make([]ValueSource, 0, len(keys))
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		s := ValueSource{
+			Key:    key,
+			Source: SourceDefault,
+		}
+
+		if env, ok := c.envs.lookup(key); ok {
+			s.Source = SourceEnv
+			s.EnvVar = env
+		} else if v.InConfig(key) {
+			s.Source = SourceFile
+		} else if !v.IsSet(key) {
+			continue
+		}
+
+		sources = append(sources, s)
+	}
+
+	return sources
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/spf13/viper"
+)
+
+func TestConfigurationSources(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector.example.com")
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	c := &Configuration{}
+	c.Bind("otel", v)
+
+	qt.Assert(t, qt.IsNil(v.ReadConfig(strings.NewReader("otel:\n  endpoint: http://localhost:4318\n  service_name: orders\n"))))
+
+	sources := make(map[string]ValueSource)
+	for _, s := range c.Sources("otel", v) {
+		sources[s.Key] = s
+	}
+
+	qt.Check(t, qt.Equals(sources["otel.endpoint"], ValueSource{Key: "otel.endpoint", Source: SourceEnv, EnvVar: "OTEL_EXPORTER_OTLP_ENDPOINT"}))
+	qt.Check(t, qt.Equals(sources["otel.service_name"], ValueSource{Key: "otel.service_name", Source: SourceFile}))
+	qt.Check(t, qt.Equals(sources["otel.exporter"], ValueSource{Key: "otel.exporter", Source: SourceDefault}))
+
+	_, ok := sources["otel.compression"]
+	qt.Check(t, qt.IsFalse(ok))
+}
+
+func TestConfigurationSourcesUnbound(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "orders")
+
+	v := viper.New()
+	(&Configuration{}).Bind("otel", v)
+
+	other := viper.New()
+	other.SetDefault("otel.service_name", "payments")
+
+	sources := (&Configuration{}).Sources("otel", other)
+	qt.Assert(t, qt.HasLen(sources, 1))
+	qt.Check(t, qt.Equals(sources[0], ValueSource{Key: "otel.service_name", Source: SourceDefault}))
+}
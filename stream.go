@@ -0,0 +1,113 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"sync/atomic"
+
+	"azugo.io/azugo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	streamMessagesSentKey     = attribute.Key("azugo.stream.messages.sent")
+	streamMessagesReceivedKey = attribute.Key("azugo.stream.messages.received")
+)
+
+// Stream traces long-lived WebSocket or server-sent events connection with a
+// span that lasts until the connection is closed and records sent and
+// received messages as span events.
+//
+// Span events are subject to the span limits of the tracer provider (128
+// events by default, see OTEL_SPAN_EVENT_COUNT_LIMIT), so for long-lived
+// connections only the first messages are recorded as events. Total message
+// counts are always recorded as span attributes when the stream ends.
+type Stream struct {
+	ctx      context.Context
+	span     trace.Span
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// StartStream starts span for the streaming connection of the request, it
+// should be called at the upgrade time. The span is an internal child span of
+// the request server span and must be ended with End when the connection is
+// closed.
+//
+// Global tracer provider is used.
+func StartStream(ctx *azugo.Context, name string, attrs ...attribute.KeyValue) *Stream {
+	return globalProviders().StartStream(ctx, name, attrs...)
+}
+
+// StartStream starts span for the streaming connection of the request the same
+// way as StartStream function using the tracer provider of the providers.
+func (p *Providers) StartStream(ctx *azugo.Context, name string, attrs ...attribute.KeyValue) *Stream {
+	tracer := p.TracerProvider.Tracer(
+		ScopeName+"/stream",
+		trace.WithInstrumentationVersion(Version()),
+		trace.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
+
+	c, span := tracer.Start(FromContext(ctx), name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+
+	return &Stream{
+		ctx:  c,
+		span: span,
+	}
+}
+
+// Context returns context with the stream span.
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// MessageSent records message of the size in bytes sent to the client. Size
+// is not recorded if it is not positive.
+func (s *Stream) MessageSent(size int) {
+	s.message(semconv.RPCMessageTypeSent, s.sent.Add(1), size)
+}
+
+// MessageReceived records message of the size in bytes received from the
+// client. Size is not recorded if it is not positive.
+func (s *Stream) MessageReceived(size int) {
+	s.message(semconv.RPCMessageTypeReceived, s.received.Add(1), size)
+}
+
+func (s *Stream) message(typ attribute.KeyValue, id int64, size int) {
+	if !s.span.IsRecording() {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, 3)
+	attrs = append(attrs, typ, semconv.RPCMessageIDKey.Int64(id))
+
+	if size > 0 {
+		attrs = append(attrs, semconv.RPCMessageUncompressedSize(size))
+	}
+
+	s.span.AddEvent("message", trace.WithAttributes(attrs...))
+}
+
+// End ends the stream span recording message counts and error the
+// connection was closed with if any.
+func (s *Stream) End(err error) {
+	s.span.SetAttributes(
+		streamMessagesSentKey.Int64(s.sent.Load()),
+		streamMessagesReceivedKey.Int64(s.received.Load()),
+	)
+
+	if err != nil {
+		s.span.SetStatus(codes.Error, err.Error())
+		s.span.RecordError(err)
+	}
+
+	s.span.End()
+}
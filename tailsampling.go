@@ -0,0 +1,188 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// defaultTailSamplingMaxSpans is the default limit of spans buffered while
+// waiting for the trace outcome.
+const defaultTailSamplingMaxSpans = 10000
+
+// tailSampler records spans that the wrapped sampler drops, so that the
+// tail sampling processor can still export them if the trace turns out to
+// be interesting.
+type tailSampler struct {
+	base trace.Sampler
+}
+
+func (s *tailSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	res := s.base.ShouldSample(p)
+	if res.Decision == trace.Drop {
+		res.Decision = trace.RecordOnly
+	}
+
+	return res
+}
+
+func (s *tailSampler) Description() string {
+	return fmt.Sprintf("TailSampler{%s}", s.base.Description())
+}
+
+// tailSamplingProcessor buffers recorded but not sampled spans until the local
+// root span of the trace ends and passes them to the wrapped span processors
+// as sampled only if the trace contains errors, server error response or
+// local root span took longer than the latency threshold. Spans ending after
+// their local root span has ended are dropped.
+type tailSamplingProcessor struct {
+	next      []trace.SpanProcessor
+	threshold time.Duration
+	maxSpans  int
+
+	mu       sync.Mutex
+	active   map[oteltrace.TraceID]int
+	traces   map[oteltrace.TraceID][]trace.ReadOnlySpan
+	buffered int
+}
+
+func newTailSamplingProcessor(config *TailSampling, next ...trace.SpanProcessor) *tailSamplingProcessor {
+	maxSpans := config.MaxBufferedSpans
+	if maxSpans <= 0 {
+		maxSpans = defaultTailSamplingMaxSpans
+	}
+
+	return &tailSamplingProcessor{
+		next:      next,
+		threshold: config.LatencyThreshold,
+		maxSpans:  maxSpans,
+		active:    make(map[oteltrace.TraceID]int),
+		traces:    make(map[oteltrace.TraceID][]trace.ReadOnlySpan),
+	}
+}
+
+// isLocalRoot reports whether span has no parent in the same process.
+func isLocalRoot(s trace.ReadOnlySpan) bool {
+	parent := s.Parent()
+
+	return !parent.IsValid() || parent.IsRemote()
+}
+
+func (p *tailSamplingProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	if !s.SpanContext().IsSampled() && isLocalRoot(s) {
+		p.mu.Lock()
+		p.active[s.SpanContext().TraceID()]++
+		p.mu.Unlock()
+	}
+
+	for _, n := range p.next {
+		n.OnStart(parent, s)
+	}
+}
+
+func (p *tailSamplingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		for _, n := range p.next {
+			n.OnEnd(s)
+		}
+
+		return
+	}
+
+	traceID := s.SpanContext().TraceID()
+
+	p.mu.Lock()
+
+	if !isLocalRoot(s) {
+		if p.active[traceID] > 0 && p.buffered < p.maxSpans {
+			p.traces[traceID] = append(p.traces[traceID], s)
+			p.buffered++
+		}
+
+		p.mu.Unlock()
+
+		return
+	}
+
+	if p.active[traceID]--; p.active[traceID] <= 0 {
+		delete(p.active, traceID)
+	}
+
+	spans := append(p.traces[traceID], s)
+	delete(p.traces, traceID)
+	p.buffered -= len(spans) - 1
+
+	p.mu.Unlock()
+
+	if !p.interesting(s, spans) {
+		return
+	}
+
+	for _, span := range spans {
+		sampled := &sampledSpan{ReadOnlySpan: span}
+		for _, n := range p.next {
+			n.OnEnd(sampled)
+		}
+	}
+}
+
+func (p *tailSamplingProcessor) interesting(root trace.ReadOnlySpan, spans []trace.ReadOnlySpan) bool {
+	if p.threshold > 0 && root.EndTime().Sub(root.StartTime()) >= p.threshold {
+		return true
+	}
+
+	for _, attr := range root.Attributes() {
+		if attr.Key == semconv.HTTPResponseStatusCodeKey && attr.Value.AsInt64() >= 500 {
+			return true
+		}
+	}
+
+	for _, span := range spans {
+		if span.Status().Code == codes.Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *tailSamplingProcessor) Shutdown(ctx context.Context) error {
+	var err error
+
+	for _, n := range p.next {
+		err = errors.Join(err, n.Shutdown(ctx))
+	}
+
+	return err
+}
+
+func (p *tailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	var err error
+
+	for _, n := range p.next {
+		err = errors.Join(err, n.ForceFlush(ctx))
+	}
+
+	return err
+}
+
+// sampledSpan is a read-only span view with sampled flag set.
+type sampledSpan struct {
+	trace.ReadOnlySpan
+}
+
+func (s *sampledSpan) SpanContext() oteltrace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
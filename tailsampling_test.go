@@ -0,0 +1,79 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
+func TestTailSampling(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	config := &TailSampling{Enabled: true}
+
+	tp := trace.NewTracerProvider(
+		trace.WithSampler(&tailSampler{base: trace.ParentBased(trace.TraceIDRatioBased(config.Ratio))}),
+		trace.WithSpanProcessor(newTailSamplingProcessor(config, trace.NewSimpleSpanProcessor(exporter))),
+	)
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "ok")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	root.End()
+
+	qt.Check(t, qt.HasLen(exporter.GetSpans(), 0))
+
+	ctx, root = tracer.Start(context.Background(), "failed")
+	_, child = tracer.Start(ctx, "child")
+	child.End()
+	root.SetAttributes(semconv.HTTPResponseStatusCode(503))
+	root.End()
+
+	spans := exporter.GetSpans()
+	qt.Assert(t, qt.HasLen(spans, 2))
+	qt.Check(t, qt.Equals(spans[0].Name, "child"))
+	qt.Check(t, qt.IsTrue(spans[0].SpanContext.IsSampled()))
+	qt.Check(t, qt.Equals(spans[1].Name, "failed"))
+
+	exporter.Reset()
+
+	ctx, root = tracer.Start(context.Background(), "ok")
+	_, child = tracer.Start(ctx, "child")
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	root.End()
+
+	qt.Check(t, qt.HasLen(exporter.GetSpans(), 2))
+}
+
+func TestTailSamplingLateChild(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	config := &TailSampling{Enabled: true}
+	processor := newTailSamplingProcessor(config, trace.NewSimpleSpanProcessor(exporter))
+
+	tp := trace.NewTracerProvider(
+		trace.WithSampler(&tailSampler{base: trace.ParentBased(trace.TraceIDRatioBased(config.Ratio))}),
+		trace.WithSpanProcessor(processor),
+	)
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "ok")
+	_, child := tracer.Start(ctx, "child")
+	root.End()
+	child.End()
+
+	qt.Check(t, qt.HasLen(exporter.GetSpans(), 0))
+	qt.Check(t, qt.HasLen(processor.traces, 0))
+	qt.Check(t, qt.HasLen(processor.active, 0))
+	qt.Check(t, qt.Equals(processor.buffered, 0))
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"azugo.io/azugo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var taskNameKey = attribute.Key("azugo.task.name")
+
+// RunTask runs scheduled or background job in a new trace with a root span
+// named after the task and "code.function" and "code.namespace" attributes of
+// the job function. Logger passed to the function has trace and span
+// identifiers of the task span, so that job logs are correlated to the trace.
+//
+// Global tracer provider is used.
+func RunTask(app *azugo.App, name string, fn func(ctx context.Context, logger *zap.Logger) error) error {
+	return globalProviders().RunTask(app, name, fn)
+}
+
+// RunTask runs scheduled or background job in a new trace the same way as
+// RunTask function using the tracer provider of the providers.
+func (p *Providers) RunTask(app *azugo.App, name string, fn func(ctx context.Context, logger *zap.Logger) error) error {
+	tracer := p.TracerProvider.Tracer(
+		ScopeName+"/task",
+		trace.WithInstrumentationVersion(Version()),
+		trace.WithInstrumentationAttributes(semconv.TelemetrySDKLanguageGo),
+	)
+
+	attrs := make([]attribute.KeyValue, 0, 3)
+	attrs = append(attrs, taskNameKey.String(name))
+	attrs = append(attrs, codeAttributes(fn)...)
+
+	ctx, span := tracer.Start(app.BackgroundContext(), "task "+name,
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	err := fn(ctx, LoggerWithSpan(ctx, app.Log()))
+	if err != nil {
+		span.SetAttributes(semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// codeAttributes returns "code.function" and "code.namespace" attributes of the function.
+func codeAttributes(fn any) []attribute.KeyValue {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return nil
+	}
+
+	name := f.Name()
+
+	// Package path can contain dots, so the namespace is split after the last slash.
+	i := strings.LastIndexByte(name, '/')
+	if j := strings.IndexByte(name[i+1:], '.'); j >= 0 {
+		i += j + 1
+
+		return []attribute.KeyValue{
+			semconv.CodeNamespace(name[:i]),
+			semconv.CodeFunction(name[i+1:]),
+		}
+	}
+
+	return []attribute.KeyValue{semconv.CodeFunction(name)}
+}
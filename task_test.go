@@ -0,0 +1,25 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.uber.org/zap"
+)
+
+func cleanupTask(_ context.Context, _ *zap.Logger) error {
+	return nil
+}
+
+func TestCodeAttributes(t *testing.T) {
+	attrs := codeAttributes(cleanupTask)
+
+	qt.Assert(t, qt.HasLen(attrs, 2))
+	qt.Check(t, qt.Equals(attrs[0], semconv.CodeNamespace("azugo.io/opentelemetry")))
+	qt.Check(t, qt.Equals(attrs[1], semconv.CodeFunction("cleanupTask")))
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+// Package telemetrybench provides helpers to measure the cost of the Azugo
+// application instrumentation in benchmarks and to catch allocation
+// regressions in tests.
+package telemetrybench
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TracerProvider returns tracer provider that samples and records all spans but
+// discards them instead of exporting, so that only the instrumentation cost is
+// measured. Use it with opentelemetry.TracerProvider option.
+func TracerProvider() *trace.TracerProvider {
+	return trace.NewTracerProvider(
+		trace.WithSampler(trace.AlwaysSample()),
+		trace.WithSpanProcessor(discard{}),
+	)
+}
+
+// Run runs fn b.N times reporting allocations.
+func Run(b *testing.B, fn func()) {
+	b.Helper()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		fn()
+	}
+}
+
+// Allocs returns the average number of allocations of fn.
+func Allocs(fn func()) float64 {
+	return testing.AllocsPerRun(100, fn)
+}
+
+// AssertAllocs asserts that fn does on average at most max allocations.
+func AssertAllocs(t testing.TB, maxAllocs float64, fn func()) {
+	t.Helper()
+
+	if allocs := Allocs(fn); allocs > maxAllocs {
+		t.Errorf("got %v allocations, want at most %v", allocs, maxAllocs)
+	}
+}
+
+// discard is a span processor that drops all ended spans.
+type discard struct{}
+
+func (discard) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (discard) OnEnd(trace.ReadOnlySpan) {}
+
+func (discard) Shutdown(context.Context) error {
+	return nil
+}
+
+func (discard) ForceFlush(context.Context) error {
+	return nil
+}
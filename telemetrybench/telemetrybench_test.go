@@ -0,0 +1,28 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetrybench
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestTracerProviderRecords(t *testing.T) {
+	_, span := TracerProvider().Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	qt.Check(t, qt.IsTrue(span.IsRecording()))
+	qt.Check(t, qt.IsTrue(span.SpanContext().IsSampled()))
+}
+
+func TestAllocs(t *testing.T) {
+	var sink []byte
+
+	qt.Check(t, qt.Equals(Allocs(func() {}), 0.0))
+	qt.Check(t, qt.Equals(Allocs(func() { sink = make([]byte, 1024) }), 1.0))
+
+	_ = sink
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"azugo.io/azugo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+var tenantIDKey = attribute.Key("tenant.id")
+
+// tenantResolver resolves tenant of the request from the user claim or header.
+type tenantResolver struct {
+	header string
+	claim  string
+	value  UserClaim
+}
+
+func newTenantResolver(config *Configuration, value UserClaim) *tenantResolver {
+	c := &config.TenantSampling
+	if len(c.Rates) == 0 && c.DefaultRate == 0 {
+		return nil
+	}
+
+	if c.Header == "" && (c.Claim == "" || value == nil) {
+		return nil
+	}
+
+	return &tenantResolver{
+		header: c.Header,
+		claim:  c.Claim,
+		value:  value,
+	}
+}
+
+// tenant returns tenant of the request. Claim of the authorized user takes
+// precedence over the header that can be set by the client.
+func (r *tenantResolver) tenant(ctx *azugo.Context) string {
+	if r.claim != "" && r.value != nil {
+		// User is only available if it has been authorized before the tracing middleware.
+		if user := ctx.User(); user != nil && user.Authorized() {
+			if v := r.value(user, r.claim); len(v) > 0 {
+				return v[0]
+			}
+		}
+	}
+
+	if r.header != "" {
+		if v := ctx.Request().Header.Peek(r.header); len(v) > 0 {
+			return string(v)
+		}
+	}
+
+	return ""
+}
+
+// tenantSampler samples spans using sampling rate configured for the
+// tenant in the "tenant.id" server span start attribute.
+type tenantSampler struct {
+	rates map[string]trace.Sampler
+	other trace.Sampler
+	next  trace.Sampler
+}
+
+// newTenantSampler returns sampler that uses next sampler for spans without tenant.
+func newTenantSampler(config *TenantSampling, next trace.Sampler) trace.Sampler {
+	rates := make(map[string]trace.Sampler, len(config.Rates))
+	for tenant, rate := range config.Rates {
+		rates[tenant] = trace.TraceIDRatioBased(rate)
+	}
+
+	other := next
+	if config.DefaultRate > 0 {
+		other = trace.TraceIDRatioBased(config.DefaultRate)
+	}
+
+	return &tenantSampler{
+		rates: rates,
+		other: other,
+		next:  next,
+	}
+}
+
+func (s *tenantSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if p.Kind == oteltrace.SpanKindServer {
+		for _, kv := range p.Attributes {
+			if kv.Key != tenantIDKey {
+				continue
+			}
+
+			if sampler, ok := s.rates[kv.Value.AsString()]; ok {
+				return sampler.ShouldSample(p)
+			}
+
+			return s.other.ShouldSample(p)
+		}
+	}
+
+	return s.next.ShouldSample(p)
+}
+
+func (s *tenantSampler) Description() string {
+	return "TenantSampler"
+}
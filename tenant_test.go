@@ -0,0 +1,47 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTenantSampler(t *testing.T) {
+	sampler := newTenantSampler(&TenantSampling{
+		Rates: map[string]float64{
+			"strategic": 1,
+			"free":      0,
+		},
+		DefaultRate: 0.000001,
+	}, trace.AlwaysSample())
+
+	tests := []struct {
+		name     string
+		attrs    []attribute.KeyValue
+		expected trace.SamplingDecision
+	}{
+		{name: "strategic", attrs: []attribute.KeyValue{tenantIDKey.String("strategic")}, expected: trace.RecordAndSample},
+		{name: "free", attrs: []attribute.KeyValue{tenantIDKey.String("free")}, expected: trace.Drop},
+		{name: "other", attrs: []attribute.KeyValue{tenantIDKey.String("other")}, expected: trace.Drop},
+		{name: "no tenant", expected: trace.RecordAndSample},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := sampler.ShouldSample(trace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       oteltrace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+				Kind:          oteltrace.SpanKindServer,
+				Attributes:    test.attrs,
+			})
+			qt.Check(t, qt.Equals(res.Decision, test.expected))
+		})
+	}
+}
@@ -3,7 +3,36 @@
 
 package opentelemetry
 
+import (
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
 // Version is the current release version of the azugo OpenTracing support.
 func Version() string {
 	return "0.1.0"
 }
+
+// BuildInfo describes the azugo OpenTelemetry support build.
+type BuildInfo struct {
+	// Version of the azugo OpenTelemetry support.
+	Version string `json:"version"`
+	// OTelVersion is the version of the OpenTelemetry API used.
+	OTelVersion string `json:"otel_version"`
+	// SchemaURL of the semantic conventions used.
+	SchemaURL string `json:"schema_url"`
+	// Features enabled by the resolved configuration. Only set by Effective.
+	Features []string `json:"features,omitempty"`
+}
+
+// Info returns build information of the azugo OpenTelemetry support to be
+// used for inventory of the telemetry capabilities of the service. Features
+// are not set as they depend on the configuration, use Effective to get build
+// information with the features enabled by the running application.
+func Info() BuildInfo {
+	return BuildInfo{
+		Version:     Version(),
+		OTelVersion: otel.Version(),
+		SchemaURL:   semconv.SchemaURL,
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// IDGeneratorRandom generates random trace IDs (default).
+	IDGeneratorRandom = "random"
+	// IDGeneratorXRay generates AWS X-Ray compatible trace IDs and enables X-Ray propagation.
+	IDGeneratorXRay = "xray"
+)
+
+const xrayTraceIDHeader = "X-Amzn-Trace-Id"
+
+// xrayIDGenerator generates trace IDs with the first 4 bytes set to the start
+// time in seconds as required by AWS X-Ray.
+type xrayIDGenerator struct{}
+
+var _ trace.IDGenerator = xrayIDGenerator{}
+
+func (g xrayIDGenerator) NewIDs(ctx context.Context) (oteltrace.TraceID, oteltrace.SpanID) {
+	var traceID oteltrace.TraceID
+
+	binary.BigEndian.PutUint32(traceID[:4], uint32(time.Now().Unix()))
+	_, _ = rand.Read(traceID[4:])
+
+	return traceID, g.NewSpanID(ctx, traceID)
+}
+
+func (xrayIDGenerator) NewSpanID(_ context.Context, _ oteltrace.TraceID) oteltrace.SpanID {
+	var spanID oteltrace.SpanID
+
+	_, _ = rand.Read(spanID[:])
+
+	return spanID
+}
+
+// xrayPropagator propagates trace context in the AWS X-Ray "X-Amzn-Trace-Id" header:
+//
+//	X-Amzn-Trace-Id: Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1
+type xrayPropagator struct{}
+
+var _ propagation.TextMapPropagator = xrayPropagator{}
+
+func (xrayPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := oteltrace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	traceID := sc.TraceID().String()
+
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+
+	var s strings.Builder
+
+	s.WriteString("Root=1-")
+	s.WriteString(traceID[:8])
+	s.WriteByte('-')
+	s.WriteString(traceID[8:])
+	s.WriteString(";Parent=")
+	s.WriteString(sc.SpanID().String())
+	s.WriteString(";Sampled=")
+	s.WriteString(sampled)
+
+	carrier.Set(xrayTraceIDHeader, s.String())
+}
+
+func (xrayPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	sc, ok := parseXRayHeader(carrier.Get(xrayTraceIDHeader))
+	if !ok {
+		return ctx
+	}
+
+	return oteltrace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+func (xrayPropagator) Fields() []string {
+	return []string{xrayTraceIDHeader}
+}
+
+func parseXRayHeader(header string) (oteltrace.SpanContext, bool) {
+	if header == "" {
+		return oteltrace.SpanContext{}, false
+	}
+
+	var cfg oteltrace.SpanContextConfig
+
+	for _, part := range strings.Split(header, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		switch k {
+		case "Root":
+			// Format: 1-<8 hex digits of time>-<24 hex digits>
+			ver, rest, ok := strings.Cut(v, "-")
+			if !ok || ver != "1" || len(rest) != 33 || rest[8] != '-' {
+				return oteltrace.SpanContext{}, false
+			}
+
+			b, err := hex.DecodeString(rest[:8] + rest[9:])
+			if err != nil {
+				return oteltrace.SpanContext{}, false
+			}
+
+			copy(cfg.TraceID[:], b)
+		case "Parent":
+			spanID, err := oteltrace.SpanIDFromHex(v)
+			if err != nil {
+				return oteltrace.SpanContext{}, false
+			}
+
+			cfg.SpanID = spanID
+		case "Sampled":
+			if v == "1" {
+				cfg.TraceFlags = oteltrace.FlagsSampled
+			}
+		}
+	}
+
+	cfg.Remote = true
+
+	sc := oteltrace.NewSpanContext(cfg)
+
+	return sc, sc.IsValid()
+}
@@ -0,0 +1,34 @@
+// Copyright 2024 Azugo
+// SPDX-License-Identifier: Apache-2.0
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestXRayPropagator(t *testing.T) {
+	const header = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+
+	p := xrayPropagator{}
+
+	ctx := p.Extract(context.Background(), propagation.MapCarrier{xrayTraceIDHeader: header})
+
+	sc := oteltrace.SpanContextFromContext(ctx)
+	qt.Assert(t, qt.IsTrue(sc.IsValid()))
+	qt.Check(t, qt.Equals(sc.TraceID().String(), "5759e988bd862e3fe1be46a994272793"))
+	qt.Check(t, qt.Equals(sc.SpanID().String(), "53995c3f42cd8ad8"))
+	qt.Check(t, qt.IsTrue(sc.IsSampled()))
+
+	carrier := propagation.MapCarrier{}
+	p.Inject(ctx, carrier)
+	qt.Check(t, qt.Equals(carrier.Get(xrayTraceIDHeader), header))
+
+	_, ok := parseXRayHeader("Root=2-5759e988-bd862e3fe1be46a994272793")
+	qt.Check(t, qt.IsFalse(ok))
+}